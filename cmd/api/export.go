@@ -0,0 +1,424 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+
+	"fahy.xyz/livetrack/internal/db"
+	"fahy.xyz/livetrack/internal/model"
+	"fahy.xyz/livetrack/internal/model/dataset"
+	"fahy.xyz/livetrack/internal/model/geojson"
+	"fahy.xyz/livetrack/internal/model/gpx"
+	"fahy.xyz/livetrack/internal/model/igc"
+	"fahy.xyz/livetrack/internal/model/kml"
+	"github.com/gorilla/mux"
+)
+
+// Formats of the format query parameter and the extension of the routes.
+const (
+	jsonFormat    = "json"
+	geoJSONFormat = "geojson"
+	kmzFormat     = "kmz"
+)
+
+// Values of the filter query parameter, the outliers are removed from the filtered tracks.
+const (
+	filteredTrack = "filtered"
+	rawTrack      = "raw"
+)
+
+var errInvalidDateRange = errors.New("the last day is before the first day")
+
+// ExportTracksGPX returns all the tracks of the day as a GPX file, with a track by pilot.
+func (h *Handler) ExportTracksGPX(w http.ResponseWriter, r *http.Request) {
+	h.logger.Info("Route triggered", "method", "GET", "route", "[/tracks/{date}.gpx]")
+
+	date, tracks, ok := h.getTracksOfDay(w, r)
+	if !ok {
+		return
+	}
+
+	h.writeGPX(w, date.Format(time.DateOnly), tracks)
+}
+
+// ExportTrackGPX returns the track of the pilot for the day as a GPX file.
+func (h *Handler) ExportTrackGPX(w http.ResponseWriter, r *http.Request) {
+	h.logger.Info("Route triggered", "method", "GET", "route", "[/track/{date}/{pilot}.gpx]")
+
+	date, pilot, points, ok := h.getTrackOfDayForPilot(w, r)
+	if !ok {
+		return
+	}
+
+	name := fmt.Sprintf("%s-%s", date.Format(time.DateOnly), pilot.Name)
+	h.writeGPX(w, name, map[string][]model.Point{pilot.Name: points})
+}
+
+// writeGPX writes the tracks as a GPX file to download, the name is the one of the file without extension.
+func (h *Handler) writeGPX(w http.ResponseWriter, name string, tracks map[string][]model.Point) {
+	file := gpx.FromTracks(name, tracks)
+
+	w.Header().Set("Content-type", gpx.ContentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name+".gpx"))
+
+	if err := file.Write(w); err != nil {
+		h.logger.Error("Error writing gpx", "error", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+}
+
+// ExportTracksKML returns all the tracks of the day as a KML or KMZ file, with a folder by pilot.
+func (h *Handler) ExportTracksKML(w http.ResponseWriter, r *http.Request) {
+	h.logger.Info("Route triggered", "method", "GET", "route", "[/tracks/{date}.{format}]")
+
+	date, tracks, ok := h.getTracksOfDay(w, r)
+	if !ok {
+		return
+	}
+
+	h.writeKML(w, date.Format(time.DateOnly), mux.Vars(r)["format"], tracks)
+}
+
+// ExportTrackKML returns the track of the pilot for the day as a KML or KMZ file.
+func (h *Handler) ExportTrackKML(w http.ResponseWriter, r *http.Request) {
+	h.logger.Info("Route triggered", "method", "GET", "route", "[/track/{date}/{pilot}.{format}]")
+
+	date, pilot, points, ok := h.getTrackOfDayForPilot(w, r)
+	if !ok {
+		return
+	}
+
+	h.writeKML(
+		w,
+		fmt.Sprintf("%s-%s", date.Format(time.DateOnly), pilot.Name),
+		mux.Vars(r)["format"],
+		map[string][]model.Point{pilot.Name: points},
+	)
+}
+
+// writeKML writes the tracks as a KML file to download, compressed if the format is kmz.
+func (h *Handler) writeKML(w http.ResponseWriter, name, format string, tracks map[string][]model.Point) {
+	file := kml.FromTracks(name, tracks)
+
+	contentType, write := kml.ContentType, file.Write
+	if format == kmzFormat {
+		contentType, write = kml.ContentTypeKMZ, file.WriteKMZ
+	}
+
+	w.Header().Set("Content-type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name+"."+format))
+
+	if err := write(w); err != nil {
+		h.logger.Error("Error writing kml", "format", format, "error", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+}
+
+// ExportTrackIGC returns the track of the pilot for the day as an unsigned IGC file.
+func (h *Handler) ExportTrackIGC(w http.ResponseWriter, r *http.Request) {
+	h.logger.Info("Route triggered", "method", "GET", "route", "[/track/{date}/{pilot}.igc]")
+
+	date, pilot, points, ok := h.getTrackOfDayForPilot(w, r)
+	if !ok {
+		return
+	}
+
+	if len(points) == 0 {
+		http.Error(w, igc.ErrNoFix.Error(), http.StatusNotFound)
+
+		return
+	}
+
+	flight := igc.FromTrack(pilot, points)
+
+	w.Header().Set("Content-type", igc.ContentType)
+	w.Header().Set(
+		"Content-Disposition",
+		fmt.Sprintf("attachment; filename=%q", fmt.Sprintf("%s-%s.igc", date.Format(time.DateOnly), pilot.Name)),
+	)
+
+	if err := flight.Write(w); err != nil {
+		h.logger.Error("Error writing igc", "error", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+}
+
+// ExportDataset streams the points of the tracks between two days as a CSV dataset.
+//
+// The pilots can be filtered by organization with the org query parameter.
+func (h *Handler) ExportDataset(w http.ResponseWriter, r *http.Request) {
+	h.logger.Info("Route triggered", "method", "GET", "route", "[/export/{from}/{to}.{format}]")
+
+	vars := mux.Vars(r)
+
+	from, to, err := parseDateRangeVars(vars["from"], vars["to"])
+	if err != nil {
+		h.logger.Error("Error retrieving parameter", "parameter", "from/to")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	source, err := db.ParseTrackSource(r.URL.Query().Get("source"))
+	if err != nil {
+		h.logger.Error("Error retrieving parameter", "parameter", "source")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	format, err := dataset.ParseFormat(vars["format"])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	raw, ok := h.wantsRawTrack(w, r)
+	if !ok {
+		return
+	}
+
+	// A season takes longer to stream than the write timeout of the server.
+	if err = http.NewResponseController(w).SetWriteDeadline(time.Time{}); err != nil {
+		h.logger.Warn("Error removing write deadline", "error", err)
+	}
+
+	w.Header().Set("Content-type", format.ContentType())
+	w.Header().Set(
+		"Content-Disposition",
+		fmt.Sprintf("attachment; filename=%q", fmt.Sprintf("%s-%s.%s", vars["from"], vars["to"], format)),
+	)
+
+	writer, err := dataset.NewWriter(format, w)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	filter := db.ExportFilter{From: from, To: to, Org: r.URL.Query().Get("org"), Source: source}
+	if err = h.manager.StreamTracks(r.Context(), filter, func(pilot string, points []model.Point) error {
+		points = filterOutliers(points, raw)
+		for i := range points {
+			if err := writer.Write(dataset.Row{Pilot: pilot, Point: &points[i]}); err != nil {
+				return fmt.Errorf("writing row: %w", err)
+			}
+		}
+
+		return nil
+	}); err != nil {
+		// The response is already started, the connection is aborted so the client does not take the truncated
+		// dataset for a complete one.
+		h.logger.Error("Error exporting dataset", "error", err)
+		panic(http.ErrAbortHandler)
+	}
+
+	if err = writer.Close(); err != nil {
+		h.logger.Error("Error closing dataset", "error", err)
+		panic(http.ErrAbortHandler)
+	}
+}
+
+// parseDateRangeVars parses the first and last days of the route, the last day must not be before the first one.
+func parseDateRangeVars(from, to string) (time.Time, time.Time, error) {
+	fromDate, err := time.Parse(time.DateOnly, from)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("parsing from date: %w", err)
+	}
+
+	toDate, err := time.Parse(time.DateOnly, to)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("parsing to date: %w", err)
+	}
+
+	if toDate.Before(fromDate) {
+		return time.Time{}, time.Time{}, errInvalidDateRange
+	}
+
+	return fromDate, toDate, nil
+}
+
+// wantsGeoJSON returns true if GeoJSON is requested, with the format query parameter or the Accept header.
+//
+// The response depends on the Accept header whatever the format, so the caches key it on the header. On an unknown
+// format, the response is written and false is returned as second value.
+func (h *Handler) wantsGeoJSON(w http.ResponseWriter, r *http.Request) (bool, bool) {
+	w.Header().Add("Vary", "Accept")
+
+	switch format := r.URL.Query().Get("format"); format {
+	case geoJSONFormat:
+		return true, true
+	case jsonFormat:
+		return false, true
+	case "":
+		return acceptsGeoJSON(r.Header.Get("Accept")), true
+	default:
+		h.logger.Error("Error retrieving parameter", "parameter", "format", "format", format)
+		http.Error(w, "unknown format: "+format, http.StatusBadRequest)
+
+		return false, false
+	}
+}
+
+// wantsRawTrack returns true unless the filtered tracks are requested with the filter query parameter, the raw
+// tracks keep the outliers.
+//
+// On an unknown filter, the response is written and false is returned as second value.
+func (h *Handler) wantsRawTrack(w http.ResponseWriter, r *http.Request) (bool, bool) {
+	switch filter := r.URL.Query().Get("filter"); filter {
+	case rawTrack, "":
+		return true, true
+	case filteredTrack:
+		return false, true
+	default:
+		h.logger.Error("Error retrieving parameter", "parameter", "filter", "filter", filter)
+		http.Error(w, "unknown filter: "+filter, http.StatusBadRequest)
+
+		return false, false
+	}
+}
+
+// filterOutliers removes the points flagged as outliers when written unless the raw track is requested, and
+// computes the statistics of the remaining points.
+func filterOutliers(points []model.Point, raw bool) []model.Point {
+	if !raw {
+		points = model.WithoutOutliers(points)
+	}
+
+	return model.ComputeStatistics(points)
+}
+
+// acceptsGeoJSON returns true if the GeoJSON media type is in the Accept header.
+func acceptsGeoJSON(accept string) bool {
+	for mediaRange := range strings.SplitSeq(accept, ",") {
+		mediaType, _, err := mime.ParseMediaType(mediaRange)
+		if err == nil && mediaType == geojson.ContentType {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (h *Handler) writeGeoJSON(w http.ResponseWriter, tracks map[string][]model.Point) {
+	w.Header().Set("Content-type", geojson.ContentType)
+
+	if err := json.NewEncoder(w).Encode(geojson.FromTracks(tracks)); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+}
+
+// getTracksOfDay returns the date of the route and the tracks of the day from the source of the query, raw unless
+// the filtered tracks are requested.
+//
+// On error, the response is written and false is returned.
+func (h *Handler) getTracksOfDay(w http.ResponseWriter, r *http.Request) (time.Time, map[string][]model.Point, bool) {
+	date, source, ok := h.parseDateAndSource(w, r)
+	if !ok {
+		return time.Time{}, nil, false
+	}
+
+	raw, ok := h.wantsRawTrack(w, r)
+	if !ok {
+		return time.Time{}, nil, false
+	}
+
+	tracks, err := h.manager.GetAllTracksOfDay(r.Context(), date, source)
+	if err != nil {
+		h.logger.Error("Error retrieving tracks", "error", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return time.Time{}, nil, false
+	}
+
+	for pilot, points := range tracks {
+		tracks[pilot] = filterOutliers(points, raw)
+	}
+
+	return date, tracks, true
+}
+
+// getTrackOfDayForPilot returns the date and the pilot of the route and the track of the pilot from the source
+// of the query, raw unless the filtered track is requested.
+//
+// On error, the response is written and false is returned.
+func (h *Handler) getTrackOfDayForPilot(
+	w http.ResponseWriter,
+	r *http.Request,
+) (time.Time, model.Pilot, []model.Point, bool) {
+	date, source, ok := h.parseDateAndSource(w, r)
+	if !ok {
+		return time.Time{}, model.Pilot{}, nil, false
+	}
+
+	raw, ok := h.wantsRawTrack(w, r)
+	if !ok {
+		return time.Time{}, model.Pilot{}, nil, false
+	}
+
+	name := mux.Vars(r)["pilot"]
+
+	var pilot model.Pilot
+
+	pilotID, err := h.manager.GetPilotID(r.Context(), name)
+	if err == nil {
+		pilot, err = h.manager.GetPilot(r.Context(), pilotID)
+	}
+
+	if err != nil {
+		h.logger.Error("Error retrieving pilot", "pilot", name, "error", err)
+
+		code := http.StatusInternalServerError
+		if errors.Is(err, db.ErrPilotNotFound) {
+			code = http.StatusNotFound
+		}
+
+		http.Error(w, err.Error(), code)
+
+		return time.Time{}, model.Pilot{}, nil, false
+	}
+
+	points, err := h.manager.GetTrackOfDay(r.Context(), pilotID, date, source)
+	if err != nil {
+		h.logger.Error("Error retrieving pilot's track", "error", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return time.Time{}, model.Pilot{}, nil, false
+	}
+
+	return date, pilot, filterOutliers(points, raw), true
+}
+
+func (h *Handler) parseDateAndSource(w http.ResponseWriter, r *http.Request) (time.Time, db.TrackSource, bool) {
+	date, err := time.Parse(time.DateOnly, mux.Vars(r)["date"])
+	if err != nil {
+		h.logger.Error("Error retrieving parameter", "parameter", "date")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return time.Time{}, "", false
+	}
+
+	source, err := db.ParseTrackSource(r.URL.Query().Get("source"))
+	if err != nil {
+		h.logger.Error("Error retrieving parameter", "parameter", "source")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return time.Time{}, "", false
+	}
+
+	return date, source, true
+}
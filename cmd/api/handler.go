@@ -3,18 +3,29 @@ package main
 import (
 	"encoding/json"
 	"errors"
+	"io"
 	"log/slog"
 	"net/http"
 	"time"
 
 	"fahy.xyz/livetrack/internal/db"
-	"github.com/gorilla/mux"
+	"fahy.xyz/livetrack/internal/model"
+	"fahy.xyz/livetrack/internal/model/flightlog"
+	"fahy.xyz/livetrack/internal/model/osmand"
+	"fahy.xyz/livetrack/internal/model/xc"
 )
 
-const numberOfDates = 5
+const (
+	numberOfDates = 5
+	// maxUploadSize is the maximum size of an uploaded flight log, a 10 hours IGC at 1s is around 3MB.
+	maxUploadSize = 10 << 20
+	// uploadTimeout is the time to receive and store an uploaded flight log, longer than the timeouts of the server.
+	uploadTimeout = 2 * time.Minute
+)
 
 type Handler struct {
 	manager *db.Manager
+	scoring xc.Config
 
 	logger  *slog.Logger
 	metrics handlerMetrics
@@ -22,9 +33,15 @@ type Handler struct {
 
 type handlerMetrics interface{}
 
-func NewHandler(manager *db.Manager, logger *slog.Logger, metrics handlerMetrics) *Handler {
+func NewHandler(
+	manager *db.Manager,
+	scoring xc.Config,
+	logger *slog.Logger,
+	metrics handlerMetrics,
+) *Handler {
 	return &Handler{
 		manager: manager,
+		scoring: scoring,
 		logger:  logger,
 		metrics: metrics,
 	}
@@ -92,18 +109,18 @@ func (h *Handler) GetPilots(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) GetTracksOfDay(w http.ResponseWriter, r *http.Request) {
 	h.logger.Info("Route triggered", "method", "GET", "route", "[/tracks/{date}]")
 
-	date, err := time.Parse("2006-01-02", mux.Vars(r)["date"])
-	if err != nil {
-		h.logger.Error("Error retrieving parameter", "parameter", "date")
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	geoJSON, ok := h.wantsGeoJSON(w, r)
+	if !ok {
+		return
+	}
 
+	_, tracks, ok := h.getTracksOfDay(w, r)
+	if !ok {
 		return
 	}
 
-	tracks, err := h.manager.GetAllTracksOfDay(r.Context(), date)
-	if err != nil {
-		h.logger.Error("Error retrieving pilots", "error", err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	if geoJSON {
+		h.writeGeoJSON(w, tracks)
 
 		return
 	}
@@ -120,33 +137,197 @@ func (h *Handler) GetTracksOfDay(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) GetTrackOfDayForPilot(w http.ResponseWriter, r *http.Request) {
 	h.logger.Info("Route triggered", "method", "GET", "route", "[/track/{date}/{pilot}]")
 
-	date, err := time.Parse("2006-01-02", mux.Vars(r)["date"])
+	geoJSON, ok := h.wantsGeoJSON(w, r)
+	if !ok {
+		return
+	}
+
+	_, pilot, tracks, ok := h.getTrackOfDayForPilot(w, r)
+	if !ok {
+		return
+	}
+
+	if geoJSON {
+		h.writeGeoJSON(w, map[string][]model.Point{pilot.Name: tracks})
+
+		return
+	}
+
+	w.Header().Set("Content-type", "application/json")
+
+	if err := json.NewEncoder(w).Encode(tracks); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+}
+
+// flight is a flight detected in a track, with its cross-country score.
+type flight struct {
+	model.Segment
+	XC xc.Result `json:"xc"`
+}
+
+// scoreFlights returns the flights detected in the points with their cross-country score.
+func (h *Handler) scoreFlights(points []model.Point) []flight {
+	segments := model.Flights(model.SplitFlights(points))
+	flights := make([]flight, 0, len(segments))
+
+	for _, segment := range segments {
+		flights = append(flights, flight{Segment: segment, XC: xc.Optimize(segment.Points, h.scoring)})
+	}
+
+	return flights
+}
+
+// GetFlightsOfDay returns the flights of the day detected in the track of each pilot, with their score.
+func (h *Handler) GetFlightsOfDay(w http.ResponseWriter, r *http.Request) {
+	h.logger.Info("Route triggered", "method", "GET", "route", "[/flights/{date}]")
+
+	_, tracks, ok := h.getTracksOfDay(w, r)
+	if !ok {
+		return
+	}
+
+	flights := make(map[string][]flight, len(tracks))
+	for pilot, points := range tracks {
+		flights[pilot] = h.scoreFlights(points)
+	}
+
+	w.Header().Set("Content-type", "application/json")
+
+	if err := json.NewEncoder(w).Encode(flights); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+}
+
+// GetFlightsOfDayForPilot returns the flights of the day detected in the track of the pilot, with their score.
+func (h *Handler) GetFlightsOfDayForPilot(w http.ResponseWriter, r *http.Request) {
+	h.logger.Info("Route triggered", "method", "GET", "route", "[/flights/{date}/{pilot}]")
+
+	_, _, points, ok := h.getTrackOfDayForPilot(w, r)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-type", "application/json")
+
+	if err := json.NewEncoder(w).Encode(h.scoreFlights(points)); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+}
+
+// IngestOsmAnd stores a position pushed with the OsmAnd protocol.
+//
+// The device is authenticated by its ID, which is the ingest token of the pilot.
+func (h *Handler) IngestOsmAnd(w http.ResponseWriter, r *http.Request) {
+	h.logger.Info("Route triggered", "method", r.Method, "route", "[/ingest/osmand]")
+
+	if err := r.ParseForm(); err != nil {
+		h.logger.Error("Error parsing form", "error", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	message, err := osmand.Parse(r.Form)
 	if err != nil {
-		h.logger.Error("Error retrieving parameter", "parameter", "date")
+		h.logger.Error("Error parsing position", "error", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	pilotID, err := h.manager.GetPilotIDByToken(r.Context(), message.Token)
+	if err != nil {
+		h.logger.Error("Error retrieving pilot ID by token", "error", err)
+
+		code := http.StatusInternalServerError
+		if errors.Is(err, db.ErrPilotNotFound) {
+			code = http.StatusUnauthorized
+		}
+
+		http.Error(w, http.StatusText(code), code)
+
+		return
+	}
+
+	// The insert triggers the notification of the new point to the SSE and the bot.
+	if err = h.manager.WriteTrack(r.Context(), pilotID, []model.Point{message.Point}); err != nil {
+		h.logger.Error("Error writing track", "ID", pilotID, "error", err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 
 		return
 	}
 
-	pilot := mux.Vars(r)["pilot"]
+	w.WriteHeader(http.StatusOK)
+}
+
+// UploadFlightLog stores the IGC or GPX flight log uploaded by a pilot after the flight.
+//
+// The file is sent in the file field of a multipart form, the pilot is authenticated by its upload token, which
+// any pilot can have whatever its tracker type.
+func (h *Handler) UploadFlightLog(w http.ResponseWriter, r *http.Request) {
+	h.logger.Info("Route triggered", "method", "POST", "route", "[/upload]")
+
+	// The server timeouts are sized for the small requests, a flight log over a slow mobile connection takes longer.
+	controller := http.NewResponseController(w)
+	if err := controller.SetReadDeadline(time.Now().Add(uploadTimeout)); err != nil {
+		h.logger.Warn("Error extending read deadline", "error", err)
+	}
 
-	pilotID, err := h.manager.GetPilotID(r.Context(), pilot)
+	if err := controller.SetWriteDeadline(time.Now().Add(uploadTimeout)); err != nil {
+		h.logger.Warn("Error extending write deadline", "error", err)
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxUploadSize)
+
+	file, header, err := r.FormFile("file")
 	if err != nil {
-		h.logger.Error("Error retrieving pilot ID", "pilot", pilot)
+		h.logger.Error("Error retrieving file", "error", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	defer file.Close()
+
+	pilotID, err := h.manager.GetPilotIDByUploadToken(r.Context(), r.FormValue("token"))
+	if err != nil {
+		h.logger.Error("Error retrieving pilot ID by token", "error", err)
 
 		code := http.StatusInternalServerError
 		if errors.Is(err, db.ErrPilotNotFound) {
-			code = http.StatusNotFound
+			code = http.StatusUnauthorized
 		}
 
-		http.Error(w, err.Error(), code)
+		http.Error(w, http.StatusText(code), code)
+
+		return
+	}
+
+	content, err := io.ReadAll(file)
+	if err != nil {
+		h.logger.Error("Error reading file", "error", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
 
 		return
 	}
 
-	tracks, err := h.manager.GetTrackOfDay(r.Context(), pilotID, date)
+	points, err := flightlog.Parse(header.Filename, content)
 	if err != nil {
-		h.logger.Error("Error retrieving pilot's track", "error", err)
+		h.logger.Error("Error parsing flight log", "file", header.Filename, "error", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+
+		return
+	}
+
+	if err = h.manager.WriteUploadedTrack(r.Context(), pilotID, points); err != nil {
+		h.logger.Error("Error writing uploaded track", "ID", pilotID, "error", err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 
 		return
@@ -154,7 +335,16 @@ func (h *Handler) GetTrackOfDayForPilot(w http.ResponseWriter, r *http.Request)
 
 	w.Header().Set("Content-type", "application/json")
 
-	if err := json.NewEncoder(w).Encode(tracks); err != nil {
+	if err := json.NewEncoder(w).Encode(
+		struct {
+			Points int       `json:"points"`
+			From   time.Time `json:"from"`
+			To     time.Time `json:"to"`
+		}{
+			Points: len(points),
+			From:   points[0].DateTime,
+			To:     points[len(points)-1].DateTime,
+		}); err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 
 		return
@@ -13,6 +13,8 @@ import (
 
 	"fahy.xyz/livetrack/internal/db"
 	"fahy.xyz/livetrack/internal/metrics"
+	"fahy.xyz/livetrack/internal/model"
+	"fahy.xyz/livetrack/internal/model/xc"
 	"github.com/gorilla/mux"
 	"github.com/kelseyhightower/envconfig"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
@@ -32,6 +34,14 @@ type envConfig struct {
 	PostgresPassword string `envconfig:"POSTGRES_PASSWORD" required:"true"     desc:"The postgres password"`
 	// Metrics
 	MetricsSubsystem string `envconfig:"METRICS_SUBSYSTEM" default:"api" desc:"The Prometheus subsystem for the metrics"`
+	// Cross-country scoring
+	XCFreeDistanceMultiplier float64 `envconfig:"XC_FREE_DISTANCE_MULTIPLIER" default:"1.0" desc:"The points by km of a free distance"`
+	XCFlatTriangleMultiplier float64 `envconfig:"XC_FLAT_TRIANGLE_MULTIPLIER" default:"1.2" desc:"The points by km of a flat triangle"`
+	XCFAITriangleMultiplier  float64 `envconfig:"XC_FAI_TRIANGLE_MULTIPLIER"  default:"1.4" desc:"The points by km of an FAI triangle"`
+	// Outlier filter
+	FilterMaxSpeed    float64 `envconfig:"FILTER_MAX_SPEED"    default:"400"  desc:"The fastest speed from the previous point, in km/h"`
+	FilterMinAltitude int     `envconfig:"FILTER_MIN_ALTITUDE" default:"-500" desc:"The lowest valid altitude, in meters"`
+	FilterMaxAltitude int     `envconfig:"FILTER_MAX_ALTITUDE" default:"9000" desc:"The highest valid altitude, in meters"`
 }
 
 const (
@@ -134,17 +144,43 @@ func run(env envConfig, logger *slog.Logger) error {
 		return fmt.Errorf("starting DB manager: %w", err)
 	}
 
+	// The points pushed to the ingest endpoint and the uploaded flight logs are flagged when written.
+	manager.SetFilter(model.FilterConfig{
+		MaxSpeed:    env.FilterMaxSpeed,
+		MinAltitude: env.FilterMinAltitude,
+		MaxAltitude: env.FilterMaxAltitude,
+	})
+
 	logger.Debug("DB manager initialized")
 
-	handler := NewHandler(manager, logger.With("component", "handler"), promMetrics)
+	scoring := xc.DefaultConfig()
+	scoring.Multipliers = xc.Multipliers{
+		FreeDistance: env.XCFreeDistanceMultiplier,
+		FlatTriangle: env.XCFlatTriangleMultiplier,
+		FAITriangle:  env.XCFAITriangleMultiplier,
+	}
+
+	handler := NewHandler(manager, scoring, logger.With("component", "handler"), promMetrics)
 	apiRouter := mux.PathPrefix("/api").Subrouter()
 
 	apiRouter.HandleFunc("/ping", handler.Ping).Methods(http.MethodGet)
 
 	apiRouter.HandleFunc("/dates", handler.GetDatesWithCount).Methods(http.MethodGet)
 	apiRouter.HandleFunc("/pilots", handler.GetPilots).Methods(http.MethodGet)
+	// The exports are registered first, the date of the other routes would match the extension.
+	apiRouter.HandleFunc("/tracks/{date}.gpx", handler.ExportTracksGPX).Methods(http.MethodGet)
+	apiRouter.HandleFunc("/track/{date}/{pilot}.gpx", handler.ExportTrackGPX).Methods(http.MethodGet)
+	apiRouter.HandleFunc("/tracks/{date}.{format:kml|kmz}", handler.ExportTracksKML).Methods(http.MethodGet)
+	apiRouter.HandleFunc("/track/{date}/{pilot}.{format:kml|kmz}", handler.ExportTrackKML).Methods(http.MethodGet)
+	apiRouter.HandleFunc("/track/{date}/{pilot}.igc", handler.ExportTrackIGC).Methods(http.MethodGet)
+	apiRouter.HandleFunc("/export/{from}/{to}.{format:csv}", handler.ExportDataset).Methods(http.MethodGet)
 	apiRouter.HandleFunc("/tracks/{date}", handler.GetTracksOfDay).Methods(http.MethodGet)
 	apiRouter.HandleFunc("/track/{date}/{pilot}", handler.GetTrackOfDayForPilot).Methods(http.MethodGet)
+	apiRouter.HandleFunc("/flights/{date}", handler.GetFlightsOfDay).Methods(http.MethodGet)
+	apiRouter.HandleFunc("/flights/{date}/{pilot}", handler.GetFlightsOfDayForPilot).Methods(http.MethodGet)
+
+	apiRouter.HandleFunc("/ingest/osmand", handler.IngestOsmAnd).Methods(http.MethodGet, http.MethodPost)
+	apiRouter.HandleFunc("/upload", handler.UploadFlightLog).Methods(http.MethodPost)
 
 	logger.Info("Livetrack api module initialized")
 
@@ -15,6 +15,8 @@ import (
 	"fahy.xyz/livetrack/internal/bot"
 	"fahy.xyz/livetrack/internal/db"
 	"fahy.xyz/livetrack/internal/metrics"
+	"fahy.xyz/livetrack/internal/model"
+	"fahy.xyz/livetrack/internal/model/xc"
 	"github.com/kelseyhightower/envconfig"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/prometheus/common/version"
@@ -40,6 +42,10 @@ type envConfig struct {
 	TelegramToken   string `envconfig:"TELEGRAM_TOKEN"   required:"true" desc:"The telegram token to use"`
 	// Metrics
 	MetricsSubsystem string `envconfig:"METRICS_SUBSYSTEM" default:"bot" desc:"The Prometheus subsystem for the metrics"`
+	// Cross-country scoring
+	XCFreeDistanceMultiplier float64 `envconfig:"XC_FREE_DISTANCE_MULTIPLIER" default:"1.0" desc:"The points by km of a free distance"`
+	XCFlatTriangleMultiplier float64 `envconfig:"XC_FLAT_TRIANGLE_MULTIPLIER" default:"1.2" desc:"The points by km of a flat triangle"`
+	XCFAITriangleMultiplier  float64 `envconfig:"XC_FAI_TRIANGLE_MULTIPLIER"  default:"1.4" desc:"The points by km of an FAI triangle"`
 }
 
 const (
@@ -153,6 +159,13 @@ func run(env envConfig, logger *slog.Logger) error {
 		return fmt.Errorf("starting telegram bot: %w", err)
 	}
 
+	scoring := xc.DefaultConfig()
+	scoring.Multipliers = xc.Multipliers{
+		FreeDistance: env.XCFreeDistanceMultiplier,
+		FlatTriangle: env.XCFlatTriangleMultiplier,
+		FAITriangle:  env.XCFAITriangleMultiplier,
+	}
+
 	taskScheduler := chrono.NewDefaultTaskScheduler()
 
 	_, err = taskScheduler.ScheduleWithCron(func(ctx context.Context) {
@@ -211,11 +224,36 @@ func run(env envConfig, logger *slog.Logger) error {
 					break
 				}
 
+				// Only warn once when the battery goes low.
+				batteryWasLow := len(pilots[i].Points) > 0 && pilots[i].Points[len(pilots[i].Points)-1].IsBatteryLow()
+				if point.IsBatteryLow() && !batteryWasLow {
+					warning := fmt.Sprintf("*%s* tracker battery is LOW", pilots[i].Name)
+					if err = bot.SendMessage(warning); err != nil {
+						logger.Error("Sending message", "msg", warning, "error", err)
+					}
+				}
+
+				// The emergency flag of a garmin tracker stays on every point until the SOS is cleared, only alert
+				// when it changes.
+				emergencyWasActive := len(pilots[i].Points) > 0 && pilots[i].Points[len(pilots[i].Points)-1].Emergency
+				if !point.Emergency && emergencyWasActive {
+					cleared := fmt.Sprintf("*%s* SOS cleared at %s", pilots[i].Name, point.DateTime.Format(time.RFC822))
+					if err = bot.SendMessage(cleared); err != nil {
+						logger.Error("Sending message", "msg", cleared, "error", err)
+					}
+				}
+
 				pilots[i].Points = append(pilots[i].Points, point)
 				msg := ""
 
-				switch point.MsgType {
-				case "OK":
+				// An SOS of a garmin tracker is as critical as a SPOT HELP message.
+				msgType := point.MsgType
+				if point.Emergency && !emergencyWasActive {
+					msgType = model.MsgTypeHelp
+				}
+
+				switch msgType {
+				case model.MsgTypeOK:
 					sbbItinerary := "No SBB itinerary"
 
 					sbbURL, err := pilots[i].GetSbbItinerary(point.Latitude, point.Longitude)
@@ -225,22 +263,46 @@ func run(env envConfig, logger *slog.Logger) error {
 						sbbItinerary = fmt.Sprintf("[Back with SBB](%s)", sbbURL)
 					}
 
+					// The statistics are computed without the bad fixes of the tracker, flagged when written.
+					flown := pilots[i]
+					flown.Points = model.WithoutOutliers(pilots[i].Points)
+					if len(flown.Points) == 0 {
+						flown.Points = pilots[i].Points
+					}
+
+					// The extremes and the score are the ones of the last flight, not of the drive to the take-off.
+					legs, scored := flown.GetLegStatistics(), flown.Points
+					if flight, ok := lastFlight(flown.Points); ok {
+						legs, scored = flight.LegStatistics, flight.Points
+					}
+
 					msg = fmt.Sprintf(
-						"*%s* sent OK at %s\nFlight time: %s\nDistance ALL/TO: %.2f/%.2f km\n%s\n%s\n%s",
+						"*%s* sent OK at %s\nFlight time: %s\nDistance ALL/TO: %.2f/%.2f km\n"+
+							"Max speed: %.1f km/h\nVario max/min: %+.1f/%+.1f m/s\nBest glide: %.1f\n%s\n%s\n%s\n%s",
 						pilots[i].Name,
 						point.DateTime.Format(time.RFC822),
-						pilots[i].GetFlightTime(),
-						pilots[i].GetCumulativeDistance(),
-						pilots[i].GetTakeOffDistance(),
+						flown.GetFlightTime(),
+						flown.GetCumulativeDistance(),
+						flown.GetTakeOffDistance(),
+						legs.MaxSpeed,
+						legs.MaxClimb,
+						legs.MaxSink,
+						legs.BestGlide,
+						formatScore(xc.Optimize(scored, scoring)),
 						pilots[i].GetLivetrackURL(env.LivetrackEndpoint),
 						point.GetItineraryURL(),
 						sbbItinerary,
 					)
-				case "HELP", "MOVE", "CUSTOM":
+				case model.MsgTypeHelp, "MOVE", "CUSTOM":
+					content := point.MsgContent
+					if content == "" {
+						content = msgType
+					}
+
 					msg = fmt.Sprintf(
 						"*%s* sent %s!!!",
 						pilots[i].Name,
-						point.MsgContent,
+						content,
 					)
 				case "START":
 					msg = fmt.Sprintf(
@@ -279,3 +341,21 @@ func run(env envConfig, logger *slog.Logger) error {
 
 	return nil
 }
+
+// lastFlight returns the last flight detected in the points, false if the pilot has not flown.
+func lastFlight(points []model.Point) (model.Segment, bool) {
+	flights := model.Flights(model.SplitFlights(points))
+	if len(flights) == 0 {
+		return model.Segment{}, false
+	}
+
+	return flights[len(flights)-1], true
+}
+
+func formatScore(result xc.Result) string {
+	if result.Best == nil {
+		return "No XC score"
+	}
+
+	return fmt.Sprintf("XC %s: %.1f km, %.1f pts", result.Best.Kind.Name(), result.Best.Distance, result.Best.Points)
+}
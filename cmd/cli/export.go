@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"fahy.xyz/livetrack/internal/db"
+	"fahy.xyz/livetrack/internal/model"
+	"fahy.xyz/livetrack/internal/model/dataset"
+)
+
+// export writes the points of the tracks, with their statistics, as a CSV dataset.
+//
+// The outliers are the points flagged when written, with the FILTER_* limits of the writer.
+func export(ctx context.Context, manager *db.Manager, args []string, logger *slog.Logger) error {
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	from := flags.String("from", time.Now().UTC().Format(dateLayout), "First day of the tracks to export (YYYY-MM-DD)")
+	to := flags.String("to", "", "Last day of the tracks to export (YYYY-MM-DD), the first day if empty")
+	org := flags.String("org", "", "Organization of the pilots to export, all if empty")
+	source := flags.String("source", string(db.SourceLive), "Source of the tracks, live or uploaded")
+	format := flags.String("format", string(dataset.FormatCSV), "Format of the dataset, only csv")
+	out := flags.String("out", "-", "File of the dataset, the standard output if -")
+	raw := flags.Bool("raw", false, "Export the outliers flagged as bad fixes, removed if false")
+
+	if err := flags.Parse(args); err != nil {
+		return fmt.Errorf("parsing flags: %w", err)
+	}
+
+	fromDate, toDate, err := parseDateRange(*from, *to)
+	if err != nil {
+		return err
+	}
+
+	trackSource, err := db.ParseTrackSource(*source)
+	if err != nil {
+		return fmt.Errorf("parsing source: %w", err)
+	}
+
+	datasetFormat, err := dataset.ParseFormat(*format)
+	if err != nil {
+		return fmt.Errorf("parsing format: %w", err)
+	}
+
+	output := os.Stdout
+
+	if *out != "-" {
+		if output, err = os.Create(*out); err != nil { //nolint:gosec // The path is given by the user of the CLI.
+			return fmt.Errorf("creating %s: %w", *out, err)
+		}
+
+		defer output.Close()
+	}
+
+	writer, err := dataset.NewWriter(datasetFormat, output)
+	if err != nil {
+		return fmt.Errorf("creating writer: %w", err)
+	}
+
+	var rows int
+
+	exportFilter := db.ExportFilter{From: fromDate, To: toDate, Org: *org, Source: trackSource}
+	if err = manager.StreamTracks(ctx, exportFilter, func(pilot string, points []model.Point) error {
+		if !*raw {
+			points = model.WithoutOutliers(points)
+		}
+
+		points = model.ComputeStatistics(points)
+		for i := range points {
+			if err := writer.Write(dataset.Row{Pilot: pilot, Point: &points[i]}); err != nil {
+				return fmt.Errorf("writing row: %w", err)
+			}
+		}
+
+		rows += len(points)
+
+		return nil
+	}); err != nil {
+		return fmt.Errorf("exporting tracks: %w", err)
+	}
+
+	if err = writer.Close(); err != nil {
+		return fmt.Errorf("closing writer: %w", err)
+	}
+
+	if *out != "-" {
+		if err = output.Close(); err != nil {
+			return fmt.Errorf("closing %s: %w", *out, err)
+		}
+	}
+
+	logger.Info("Tracks exported", "rows", rows, "from", fromDate, "to", toDate, "org", *org, "format", datasetFormat)
+
+	return nil
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"fahy.xyz/livetrack/internal/db"
+	"fahy.xyz/livetrack/internal/model/igc"
+)
+
+// exportIGC writes an unsigned IGC file by pilot and day with points, to export a whole season.
+func exportIGC(ctx context.Context, manager *db.Manager, args []string, logger *slog.Logger) error {
+	flags := flag.NewFlagSet("igc", flag.ContinueOnError)
+	from := flags.String("from", time.Now().UTC().Format(dateLayout), "First day of the tracks to export (YYYY-MM-DD)")
+	to := flags.String("to", "", "Last day of the tracks to export (YYYY-MM-DD), the first day if empty")
+	pilotID := flags.String("pilot", "", "ID of the pilot to export, all if empty")
+	source := flags.String("source", string(db.SourceLive), "Source of the tracks, live or uploaded")
+	out := flags.String("out", ".", "Directory of the IGC files")
+
+	if err := flags.Parse(args); err != nil {
+		return fmt.Errorf("parsing flags: %w", err)
+	}
+
+	fromDate, toDate, err := parseDateRange(*from, *to)
+	if err != nil {
+		return err
+	}
+
+	trackSource, err := db.ParseTrackSource(*source)
+	if err != nil {
+		return fmt.Errorf("parsing source: %w", err)
+	}
+
+	pilots, err := manager.GetAllPilots(ctx)
+	if err != nil {
+		return fmt.Errorf("retrieving pilots: %w", err)
+	}
+
+	if err = os.MkdirAll(*out, 0o755); err != nil { //nolint:mnd // Usual permissions of a directory.
+		return fmt.Errorf("creating output directory: %w", err)
+	}
+
+	var written int
+
+	for day := fromDate; !day.After(toDate); day = day.AddDate(0, 0, 1) {
+		for _, pilot := range pilots {
+			if *pilotID != "" && pilot.ID != *pilotID {
+				continue
+			}
+
+			points, err := manager.GetTrackOfDay(ctx, pilot.ID, day, trackSource)
+			if err != nil {
+				return fmt.Errorf("retrieving track of %s: %w", pilot.Name, err)
+			}
+
+			if len(points) == 0 {
+				continue
+			}
+
+			path := filepath.Join(*out, fmt.Sprintf("%s-%s.igc", day.Format(dateLayout), pilot.Name))
+			if err = writeIGC(path, igc.FromTrack(pilot, points)); err != nil {
+				return err
+			}
+
+			logger.Info("Flight log written", "file", path, "points", len(points))
+
+			written++
+		}
+	}
+
+	logger.Info("Tracks exported", "files", written, "from", fromDate, "to", toDate, "source", trackSource)
+
+	return nil
+}
+
+func writeIGC(path string, flight igc.Flight) error {
+	file, err := os.Create(path) //nolint:gosec // The path is given by the user of the CLI.
+	if err != nil {
+		return fmt.Errorf("creating %s: %w", path, err)
+	}
+
+	defer file.Close()
+
+	if err = flight.Write(file); err != nil {
+		return fmt.Errorf("writing %s: %w", path, err)
+	}
+
+	if err = file.Close(); err != nil {
+		return fmt.Errorf("closing %s: %w", path, err)
+	}
+
+	return nil
+}
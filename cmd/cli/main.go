@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"fahy.xyz/livetrack/internal/db"
+	"fahy.xyz/livetrack/internal/metrics"
+	"fahy.xyz/livetrack/internal/model"
+	"github.com/kelseyhightower/envconfig"
+)
+
+type envConfig struct {
+	// Logging
+	LogLevel *slog.LevelVar `envconfig:"LOG_LEVEL" default:"info" desc:"The log level"`
+	// Postgres config
+	PostgresHost     string `envconfig:"POSTGRES_HOST"     default:"localhost" desc:"The postgres host"`
+	PostgresPort     int    `envconfig:"POSTGRES_PORT"     default:"5432"      desc:"The postgres port"`
+	PostgresDBName   string `envconfig:"POSTGRES_DB_NAME"  default:"tracking"  desc:"The postgres database name"`
+	PostgresUser     string `envconfig:"POSTGRES_USER"     required:"true"     desc:"The postgres user"`
+	PostgresPassword string `envconfig:"POSTGRES_PASSWORD" required:"true"     desc:"The postgres password"`
+	// Metrics
+	MetricsSubsystem string `envconfig:"METRICS_SUBSYSTEM" default:"cli" desc:"The Prometheus subsystem for the metrics"`
+	// Outlier filter
+	FilterMaxSpeed    float64 `envconfig:"FILTER_MAX_SPEED"    default:"400"  desc:"The fastest speed from the previous point, in km/h"`
+	FilterMinAltitude int     `envconfig:"FILTER_MIN_ALTITUDE" default:"-500" desc:"The lowest valid altitude, in meters"`
+	FilterMaxAltitude int     `envconfig:"FILTER_MAX_ALTITUDE" default:"9000" desc:"The highest valid altitude, in meters"`
+}
+
+var errUnknownCommand = errors.New("unknown command")
+
+const usage = `Usage: livetrack-cli <command> [flags]
+
+Commands:
+  replay    Parse the archived payloads and write the points again
+  upload    Store IGC or GPX flight logs as the uploaded track of a pilot
+  igc       Write the stored tracks as IGC files, by pilot and day
+  export    Write the points of the stored tracks with their statistics as CSV
+`
+
+func main() {
+	var env envConfig
+	if err := envconfig.Process("", &env); err != nil {
+		slog.Default().Error("Processing env var", "error", err)
+		os.Exit(1)
+	}
+
+	var handler slog.Handler = slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{Level: env.LogLevel})
+
+	logger := slog.New(handler)
+
+	if len(os.Args) < 2 { //nolint:mnd // The command name and the subcommand.
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2) //nolint:mnd // Same exit code as the flag package.
+	}
+
+	if err := run(env, os.Args[1], os.Args[2:], logger); err != nil {
+		logger.Error("running livetrack-cli", "error", err)
+		os.Exit(1)
+	}
+}
+
+func run(env envConfig, command string, args []string, logger *slog.Logger) error {
+	logger = logger.With("component", "cli", "command", command)
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	promMetrics, _, err := metrics.NewPrometheusMetrics(env.MetricsSubsystem)
+	if err != nil {
+		return fmt.Errorf("creating Prometheus metrics: %w", err)
+	}
+
+	databaseURL := fmt.Sprintf(
+		"postgres://%s:%s@%s:%d/%s?sslmode=disable",
+		env.PostgresUser, env.PostgresPassword,
+		env.PostgresHost, env.PostgresPort, env.PostgresDBName,
+	)
+
+	manager, err := db.NewManager(ctx, databaseURL, logger.With("component", "manager"), promMetrics)
+	if err != nil {
+		return fmt.Errorf("starting DB manager: %w", err)
+	}
+
+	defer manager.Close()
+
+	// The replayed and the uploaded points are flagged when written, the same as the fetcher and the API.
+	manager.SetFilter(model.FilterConfig{
+		MaxSpeed:    env.FilterMaxSpeed,
+		MinAltitude: env.FilterMinAltitude,
+		MaxAltitude: env.FilterMaxAltitude,
+	})
+
+	switch command {
+	case "replay":
+		return replay(ctx, manager, args, logger, promMetrics)
+	case "upload":
+		return upload(ctx, manager, args, logger)
+	case "igc":
+		return exportIGC(ctx, manager, args, logger)
+	case "export":
+		return export(ctx, manager, args, logger)
+	default:
+		fmt.Fprint(os.Stderr, usage)
+
+		return fmt.Errorf("%w: %s", errUnknownCommand, command)
+	}
+}
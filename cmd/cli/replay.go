@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"fahy.xyz/livetrack/internal/db"
+	"fahy.xyz/livetrack/internal/fetcher"
+	"fahy.xyz/livetrack/internal/metrics"
+	"fahy.xyz/livetrack/internal/model"
+)
+
+const dateLayout = "2006-01-02"
+
+// replay parses the archived payloads again and writes their points.
+//
+// It is used to recover the points after a bug in a parser, the points already
+// stored are left untouched.
+func replay(
+	ctx context.Context,
+	manager *db.Manager,
+	args []string,
+	logger *slog.Logger,
+	promMetrics *metrics.Prometheus,
+) error {
+	flags := flag.NewFlagSet("replay", flag.ContinueOnError)
+	from := flags.String("from", time.Now().UTC().Format(dateLayout), "First day of the payloads to replay (YYYY-MM-DD)")
+	to := flags.String("to", "", "Last day of the payloads to replay (YYYY-MM-DD), the first day if empty")
+	source := flags.String("source", "", "Tracker type of the payloads to replay, all if empty")
+	pilotID := flags.String("pilot", "", "ID of the pilot to replay, all if empty")
+	dryRun := flags.Bool("dry-run", false, "Parse the payloads without writing the points")
+
+	if err := flags.Parse(args); err != nil {
+		return fmt.Errorf("parsing flags: %w", err)
+	}
+
+	fromDate, toDate, err := parseDateRange(*from, *to)
+	if err != nil {
+		return err
+	}
+
+	registry := fetcher.NewRegistry(
+		fetcher.NewGarminFetcher("", logger.With("component", "garmin-fetcher"), promMetrics),
+		fetcher.NewSpotFetcher("", logger.With("component", "spot-fetcher"), promMetrics),
+		fetcher.NewLivetrack24Fetcher("", logger.With("component", "livetrack24-fetcher"), promMetrics),
+	)
+
+	logger.Info("Replaying payloads", "from", fromDate, "to", toDate, "source", *source, "pilotID", *pilotID)
+
+	var written int
+
+	last := toDate.AddDate(0, 0, 1)
+
+	err = manager.ForEachPayload(ctx, *source, *pilotID, fromDate, last, func(payload model.Payload) error {
+		// The errors of the sources have no point to replay.
+		if !payload.IsSuccess() {
+			return nil
+		}
+
+		points, err := registry.ParsePayload(payload)
+		if err != nil {
+			logger.Error("Parsing payload", "ID", payload.ID, "source", payload.Source, "error", err)
+
+			return nil
+		}
+
+		if len(points) == 0 || *dryRun {
+			logger.Info("Payload parsed", "ID", payload.ID, "pilotID", payload.PilotID, "points", len(points))
+
+			return nil
+		}
+
+		if err = manager.WriteTrack(ctx, payload.PilotID, points); err != nil {
+			return fmt.Errorf("writing track of payload %d: %w", payload.ID, err)
+		}
+
+		written += len(points)
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("replaying payloads: %w", err)
+	}
+
+	logger.Info("Payloads replayed", "points", written)
+
+	return nil
+}
+
+// parseDateRange parses the first and last days of the flags, the last day is the first one if empty.
+func parseDateRange(from, to string) (time.Time, time.Time, error) {
+	fromDate, err := time.Parse(dateLayout, from)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("parsing from date: %w", err)
+	}
+
+	toDate := fromDate
+
+	if to != "" {
+		if toDate, err = time.Parse(dateLayout, to); err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("parsing to date: %w", err)
+		}
+	}
+
+	return fromDate, toDate, nil
+}
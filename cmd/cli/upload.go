@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"fahy.xyz/livetrack/internal/db"
+	"fahy.xyz/livetrack/internal/model/flightlog"
+)
+
+var errMissingArgument = errors.New("missing argument")
+
+// upload stores the IGC or GPX flight logs of a pilot as its uploaded track.
+//
+// The uploaded track is kept apart from the live points, uploading a file again replaces its points.
+func upload(ctx context.Context, manager *db.Manager, args []string, logger *slog.Logger) error {
+	flags := flag.NewFlagSet("upload", flag.ContinueOnError)
+	pilotID := flags.String("pilot", "", "ID of the pilot of the flight logs")
+	dryRun := flags.Bool("dry-run", false, "Parse the flight logs without writing the points")
+
+	if err := flags.Parse(args); err != nil {
+		return fmt.Errorf("parsing flags: %w", err)
+	}
+
+	if *pilotID == "" {
+		return fmt.Errorf("%w: -pilot", errMissingArgument)
+	}
+
+	if flags.NArg() == 0 {
+		return fmt.Errorf("%w: flight log files", errMissingArgument)
+	}
+
+	for _, filename := range flags.Args() {
+		content, err := os.ReadFile(filename)
+		if err != nil {
+			return fmt.Errorf("reading %s: %w", filename, err)
+		}
+
+		points, err := flightlog.Parse(filename, content)
+		if err != nil {
+			return fmt.Errorf("parsing %s: %w", filename, err)
+		}
+
+		logger.Info("Flight log parsed",
+			"file", filename,
+			"points", len(points),
+			"from", points[0].DateTime,
+			"to", points[len(points)-1].DateTime,
+		)
+
+		if *dryRun {
+			continue
+		}
+
+		if err = manager.WriteUploadedTrack(ctx, *pilotID, points); err != nil {
+			return fmt.Errorf("writing uploaded track of %s: %w", filename, err)
+		}
+	}
+
+	return nil
+}
@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"slices"
 	"syscall"
 	"time"
 
@@ -33,19 +34,36 @@ type envConfig struct {
 	PostgresUser     string `envconfig:"POSTGRES_USER"     required:"true"     desc:"The postgres user"`
 	PostgresPassword string `envconfig:"POSTGRES_PASSWORD" required:"true"     desc:"The postgres password"`
 	// Fetchers
-	SpotBaseURL   string `envconfig:"SPOT_BASE_URL"   default:"https://api.findmespot.com/spot-main-web/consumer/rest-api/2.0/public/feed/" desc:"The base URL for the SPOT tracking"`
-	GarminBaseURL string `envconfig:"GARMIN_BASE_URL" default:"https://share.garmin.com/Feed/Share/"                                        desc:"The base URL for the garmin tracking"`
+	SpotBaseURL        string `envconfig:"SPOT_BASE_URL"        default:"https://api.findmespot.com/spot-main-web/consumer/rest-api/2.0/public/feed/" desc:"The base URL for the SPOT tracking"`
+	GarminBaseURL      string `envconfig:"GARMIN_BASE_URL"      default:"https://share.garmin.com/Feed/Share/"                                        desc:"The base URL for the garmin tracking"`
+	Livetrack24BaseURL string `envconfig:"LIVETRACK24_BASE_URL" default:""                                                                            desc:"The base URL for the livetrack24 tracking, disabled if empty"`
 	// Behaviour settings
-	FetchInterval time.Duration `envconfig:"FETCH_INTERVAL" default:"4m" desc:"The interval between two fetches"`
+	FetchInterval  time.Duration            `envconfig:"FETCH_INTERVAL"  default:"4m"                               desc:"The interval between two fetches"`
+	FetchLookback  time.Duration            `envconfig:"FETCH_LOOKBACK"  default:"30m"                              desc:"The window before the last point to fetch again for late messages"`
+	FetchWorkers   int                      `envconfig:"FETCH_WORKERS"   default:"4"                                desc:"The number of trackers fetched at the same time"`
+	FetchIntervals map[string]time.Duration `envconfig:"FETCH_INTERVALS" default:"spot:2s,garmin:1s,livetrack24:1s" desc:"The minimum delay between two requests by tracker type"`
+	// Open Glider Network
+	OGNAddress     string        `envconfig:"OGN_ADDRESS"      default:"aprs.glidernet.org:14580" desc:"The APRS-IS server of the Open Glider Network"`
+	OGNCallsign    string        `envconfig:"OGN_CALLSIGN"     default:"LIVETRACK"                desc:"The callsign used to log in the APRS-IS server"`
+	OGNMinInterval time.Duration `envconfig:"OGN_MIN_INTERVAL" default:"30s"                      desc:"The minimum delay between two stored points of a device"`
+	// Meshtastic
+	MeshtasticBroker   string       `envconfig:"MESHTASTIC_BROKER"    default:""               desc:"The MQTT broker of the Meshtastic gateways, e.g. tcp://mqtt.meshtastic.org:1883, disabled if empty"`
+	MeshtasticTopic    string       `envconfig:"MESHTASTIC_TOPIC"     default:"msh/+/2/json/#" desc:"The MQTT topic of the Meshtastic JSON packets"`
+	MeshtasticClientID string       `envconfig:"MESHTASTIC_CLIENT_ID" default:"livetrack"      desc:"The MQTT client ID"`
+	MeshtasticUsername string       `envconfig:"MESHTASTIC_USERNAME"  default:""               desc:"The MQTT username"`
+	MeshtasticPassword model.Secret `envconfig:"MESHTASTIC_PASSWORD"  default:""               desc:"The MQTT password"`
+	// Archive
+	ArchivePayloads  bool          `envconfig:"ARCHIVE_PAYLOADS"  default:"false" desc:"Archive the raw payloads of the trackers"`
+	ArchiveRetention time.Duration `envconfig:"ARCHIVE_RETENTION" default:"720h"  desc:"The duration the raw payloads are kept"`
+	// Outlier filter
+	FilterMaxSpeed    float64 `envconfig:"FILTER_MAX_SPEED"    default:"400"  desc:"The fastest speed from the previous point, in km/h"`
+	FilterMinAltitude int     `envconfig:"FILTER_MIN_ALTITUDE" default:"-500" desc:"The lowest valid altitude, in meters"`
+	FilterMaxAltitude int     `envconfig:"FILTER_MAX_ALTITUDE" default:"9000" desc:"The highest valid altitude, in meters"`
 	// Metrics
 	MetricsSubsystem string `envconfig:"METRICS_SUBSYSTEM" default:"fetcher" desc:"The Prometheus subsystem for the metrics"`
 }
 
 const (
-	garminTracker = "garmin"
-	spotTracker   = "spot"
-	fetchDelay    = 5 * time.Second
-
 	defaultReadTimeout    = 5 * time.Second
 	defaultWriteTimeout   = 10 * time.Second
 	defaultIdleTimeout    = 30 * time.Second
@@ -69,7 +87,6 @@ func main() {
 	}
 }
 
-//nolint:cyclop // To be refactored.
 func run(env envConfig, logger *slog.Logger) error {
 	logger.Info("Livetrack fetcher is initializing...",
 		"version", version.Version,
@@ -146,6 +163,13 @@ func run(env envConfig, logger *slog.Logger) error {
 		return fmt.Errorf("starting DB manager: %w", err)
 	}
 
+	// The points of the trackers are flagged when written.
+	manager.SetFilter(model.FilterConfig{
+		MaxSpeed:    env.FilterMaxSpeed,
+		MinAltitude: env.FilterMinAltitude,
+		MaxAltitude: env.FilterMaxAltitude,
+	})
+
 	logger.Debug("DB manager initialized")
 
 	pilots, err := manager.GetAllPilots(ctx)
@@ -155,6 +179,83 @@ func run(env envConfig, logger *slog.Logger) error {
 
 	garminFetcher := fetcher.NewGarminFetcher(env.GarminBaseURL, logger.With("component", "garmin-fetcher"), promMetrics)
 	spotFetcher := fetcher.NewSpotFetcher(env.SpotBaseURL, logger.With("component", "spot-fetcher"), promMetrics)
+	livetrack24Fetcher := fetcher.NewLivetrack24Fetcher(
+		env.Livetrack24BaseURL,
+		logger.With("component", "livetrack24-fetcher"),
+		promMetrics,
+	)
+
+	if env.ArchivePayloads {
+		garminFetcher.SetArchive(manager)
+		spotFetcher.SetArchive(manager)
+		livetrack24Fetcher.SetArchive(manager)
+	}
+
+	garminFetcher.SetRateLimit(env.FetchIntervals[fetcher.GarminTracker])
+	spotFetcher.SetRateLimit(env.FetchIntervals[fetcher.SpotTracker])
+	livetrack24Fetcher.SetRateLimit(env.FetchIntervals[fetcher.Livetrack24Tracker])
+
+	registry := fetcher.NewRegistry(garminFetcher, spotFetcher)
+
+	// The livetrack24 API is not checked against a recorded response yet, the pilots of this tracker type fail
+	// with an unknown tracker error until it is enabled.
+	if env.Livetrack24BaseURL != "" {
+		logger.Warn("Livetrack24 fetcher enabled, its URL and format are not checked against the API yet")
+		registry.Register(livetrack24Fetcher)
+	}
+
+	orchestrator := fetcher.NewOrchestrator(
+		registry,
+		manager,
+		env.FetchWorkers,
+		env.FetchLookback,
+		logger.With("component", "orchestrator"),
+		promMetrics,
+	)
+
+	// The positions of the OGN devices are pushed, the push trackers are not fetched by the orchestrator.
+	ognReceiver := fetcher.NewOGNReceiver(
+		env.OGNAddress,
+		env.OGNCallsign,
+		env.OGNMinInterval,
+		manager,
+		logger.With("component", "ogn-receiver"),
+		promMetrics,
+	)
+	ognReceiver.UpdatePilots(pilots)
+
+	ctxPool.Go(func(ctx context.Context) error {
+		if err := ognReceiver.Run(ctx); err != nil {
+			return fmt.Errorf("OGN receiver crashed: %w", err)
+		}
+
+		return nil
+	})
+
+	// The Meshtastic nodes publish their positions through the gateways to the broker.
+	meshtasticReceiver := fetcher.NewMeshtasticReceiver(
+		fetcher.MeshtasticBroker{
+			URL:      env.MeshtasticBroker,
+			Topic:    env.MeshtasticTopic,
+			ClientID: env.MeshtasticClientID,
+			Username: env.MeshtasticUsername,
+			Password: env.MeshtasticPassword,
+		},
+		manager,
+		logger.With("component", "meshtastic-receiver"),
+		promMetrics,
+	)
+	meshtasticReceiver.UpdatePilots(pilots)
+
+	if env.MeshtasticBroker != "" {
+		ctxPool.Go(func(ctx context.Context) error {
+			if err := meshtasticReceiver.Run(ctx); err != nil {
+				return fmt.Errorf("meshtastic receiver crashed: %w", err)
+			}
+
+			return nil
+		})
+	}
 
 	taskScheduler := chrono.NewDefaultTaskScheduler()
 
@@ -168,45 +269,34 @@ func run(env envConfig, logger *slog.Logger) error {
 
 			return
 		}
-	}, "0 0 0 * * *")
-
-	_, err = taskScheduler.ScheduleWithFixedDelay(func(ctx context.Context) {
-		logger.Info("Fetching tracker sources", "time", time.Now())
 
-		for _, pilot := range pilots {
-			var points []model.Point
-
-			switch pilot.TrackerType {
-			case garminTracker:
-				points, err = garminFetcher.Fetch(ctx, pilot.ID)
-				if err != nil {
-					logger.Error("Retrieving tracker for garmin", "ID", pilot.ID, "error", err)
-
-					continue
-				}
-			case spotTracker:
-				points, err = spotFetcher.Fetch(ctx, pilot.ID)
-				if err != nil {
-					logger.Error("Retrieving tracker for spot", "ID", pilot.ID, "error", err)
+		ognReceiver.UpdatePilots(pilots)
+		meshtasticReceiver.UpdatePilots(pilots)
+	}, "0 0 0 * * *")
 
-					continue
-				}
-			default:
-				logger.Error("Unknown tracker", "pilot", pilot)
+	if env.ArchivePayloads {
+		// Remove the payloads older than the retention each day.
+		_, err = taskScheduler.ScheduleWithCron(func(ctx context.Context) {
+			count, err := manager.DeletePayloadsBefore(ctx, time.Now().Add(-env.ArchiveRetention))
+			if err != nil {
+				logger.Error("Deleting payloads", "error", err)
 
-				continue
+				return
 			}
 
-			logger.Debug("Fetched", "points", points)
+			logger.Info("Payloads deleted", "count", count)
+		}, "0 30 0 * * *")
+		if err != nil {
+			return fmt.Errorf("scheduling payloads cleanup: %w", err)
+		}
+	}
 
-			if len(points) > 0 {
-				if err = manager.WriteTrack(ctx, pilot.ID, points); err != nil {
-					logger.Error("Writing track", "ID", pilot.ID, "track", points, "error", err)
-				}
-			}
+	_, err = taskScheduler.ScheduleWithFixedDelay(func(ctx context.Context) {
+		logger.Info("Fetching tracker sources", "time", time.Now())
 
-			time.Sleep(fetchDelay)
-		}
+		orchestrator.Run(ctx, slices.DeleteFunc(slices.Clone(pilots), func(pilot model.Pilot) bool {
+			return fetcher.IsPushTracker(pilot.TrackerType)
+		}))
 	}, env.FetchInterval)
 
 	if err == nil {
@@ -0,0 +1,110 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"fahy.xyz/livetrack/internal/model"
+	"github.com/jackc/pgx/v5"
+)
+
+// ExportFilter selects the tracks of a bulk export.
+type ExportFilter struct {
+	// From and To are the first and the last days of the export.
+	From time.Time
+	To   time.Time
+	// Org keeps the pilots of the organization, all the pilots if empty.
+	Org    string
+	Source TrackSource
+}
+
+type exportPoint struct {
+	PilotName string    `db:"pilot_name"`
+	Day       time.Time `db:"day"`
+	model.Point
+}
+
+// StreamTracks calls fn with the track of each pilot and day of the filter, with the statistics computed.
+//
+// The tracks are sorted by day and pilot name. The points are read from the database as fn is called,
+// so only one track is in memory, and the slice is reused after fn returns.
+func (m *Manager) StreamTracks(
+	ctx context.Context,
+	filter ExportFilter,
+	fn func(pilot string, points []model.Point) error,
+) error {
+	m.logger.Debug("Streaming tracks", "filter", filter)
+
+	rows, err := m.client.Query(
+		ctx,
+		//nolint:gosec // The table is one of the constants of the sources.
+		fmt.Sprintf(`SELECT p.name AS pilot_name, DATE(t.unix_time) AS day, t.unix_time, t.latitude, t.longitude,
+		        t.altitude, t.msg_type, t.msg_content, t.device_model, t.messenger_name, t.imei, t.battery_state,
+		        t.hidden, t.valid_gps_fix, t.velocity, t.course, t.emergency, t.outlier
+		 FROM %s t
+		 JOIN pilot p ON p.id = t.pilot_id
+		 WHERE DATE(t.unix_time) BETWEEN $1::date AND $2::date AND ($3::text = '' OR $3::text = ANY(p.orgs))
+		 ORDER BY day, p.name, t.unix_time`, filter.Source.table()),
+		filter.From.Format("2006-01-02"),
+		filter.To.Format("2006-01-02"),
+		filter.Org,
+	)
+	if err != nil {
+		return fmt.Errorf("querying tracks: %w", err)
+	}
+
+	defer rows.Close()
+
+	var (
+		pilot  string
+		day    time.Time
+		points []model.Point
+		tracks int
+	)
+
+	flush := func() error {
+		if len(points) == 0 {
+			return nil
+		}
+
+		if err := fn(pilot, model.ComputeStatistics(points)); err != nil {
+			return err
+		}
+
+		points = points[:0]
+		tracks++
+
+		return nil
+	}
+
+	for rows.Next() {
+		point, err := pgx.RowToStructByNameLax[exportPoint](rows)
+		if err != nil {
+			return fmt.Errorf("scanning row: %w", err)
+		}
+
+		if point.PilotName != pilot || !point.Day.Equal(day) {
+			if err = flush(); err != nil {
+				return err
+			}
+
+			pilot, day = point.PilotName, point.Day
+		}
+
+		points = append(points, point.Point)
+	}
+
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("reading rows: %w", err)
+	}
+
+	if err = flush(); err != nil {
+		return err
+	}
+
+	m.logger.Debug("Tracks streamed", "filter", filter, "tracks", tracks)
+	m.metrics.TrackRetrieved()
+
+	return nil
+}
@@ -5,12 +5,13 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"slices"
 	"time"
 
 	"fahy.xyz/livetrack/internal/model"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
-	"github.com/lib/pq"
 )
 
 const errDuplicateKey = "23505"
@@ -22,6 +23,7 @@ var (
 
 type Manager struct {
 	client  *pgxpool.Pool
+	filter  model.FilterConfig
 	logger  *slog.Logger
 	metrics managerMetrics
 }
@@ -56,6 +58,7 @@ func NewManager(
 
 	manager := &Manager{
 		client:  conn,
+		filter:  model.DefaultFilterConfig(),
 		logger:  logger,
 		metrics: metrics,
 	}
@@ -64,6 +67,11 @@ func NewManager(
 	return manager, nil
 }
 
+// SetFilter sets the limits of the valid points, the points written out of them are flagged as outliers.
+func (m *Manager) SetFilter(filter model.FilterConfig) {
+	m.filter = filter
+}
+
 func (m *Manager) Ping(ctx context.Context) error {
 	if err := m.client.Ping(ctx); err != nil {
 		return fmt.Errorf("pinging database: %w", err)
@@ -77,7 +85,10 @@ func (m *Manager) Close() {
 }
 
 func (m *Manager) GetAllPilots(ctx context.Context) ([]model.Pilot, error) {
-	rows, err := m.client.Query(ctx, "SELECT id, name, home, orgs, tracker_type FROM pilot")
+	rows, err := m.client.Query(
+		ctx,
+		"SELECT id, name, home, orgs, tracker_type, COALESCE(feed_password, '') AS feed_password FROM pilot",
+	)
 	if err != nil {
 		return nil, fmt.Errorf("querying pilots: %w", err)
 	}
@@ -160,6 +171,64 @@ func (m *Manager) GetPilotID(ctx context.Context, name string) (string, error) {
 	return pilotID, nil
 }
 
+// GetPilot returns the pilot with the given ID.
+func (m *Manager) GetPilot(ctx context.Context, pilotID string) (model.Pilot, error) {
+	rows, err := m.client.Query(ctx, "SELECT id, name, home, orgs, tracker_type FROM pilot WHERE id = $1", pilotID)
+	if err != nil {
+		return model.Pilot{}, fmt.Errorf("querying pilot %s: %w", pilotID, err)
+	}
+
+	defer rows.Close()
+
+	pilot, err := pgx.CollectOneRow(rows, pgx.RowToStructByNameLax[model.Pilot])
+	if errors.Is(err, pgx.ErrNoRows) {
+		return model.Pilot{}, ErrPilotNotFound
+	}
+
+	if err != nil {
+		return model.Pilot{}, fmt.Errorf("collecting row: %w", err)
+	}
+
+	m.logger.Debug("Pilot retrieved", "pilot", pilot)
+	m.metrics.PilotRetrieved()
+
+	return pilot, nil
+}
+
+// GetPilotIDByToken returns the ID of the pilot pushing its positions with the given token.
+func (m *Manager) GetPilotIDByToken(ctx context.Context, token string) (string, error) {
+	return m.getPilotIDBy(ctx, "SELECT id FROM pilot WHERE ingest_token = $1", token)
+}
+
+// GetPilotIDByUploadToken returns the ID of the pilot uploading its flight logs with the given token.
+func (m *Manager) GetPilotIDByUploadToken(ctx context.Context, token string) (string, error) {
+	return m.getPilotIDBy(ctx, "SELECT id FROM pilot WHERE upload_token = $1", token)
+}
+
+// getPilotIDBy returns the ID of the pilot selected by the query with the token.
+func (m *Manager) getPilotIDBy(ctx context.Context, query, token string) (string, error) {
+	rows, err := m.client.Query(ctx, query, token)
+	if err != nil {
+		return "", fmt.Errorf("querying pilots: %w", err)
+	}
+
+	defer rows.Close()
+
+	pilots, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return "", fmt.Errorf("collecting rows: %w", err)
+	}
+
+	if len(pilots) == 0 {
+		return "", ErrPilotNotFound
+	}
+
+	m.logger.Debug("Pilot ID retrieved by token", "ID", pilots[0])
+	m.metrics.PilotRetrieved()
+
+	return pilots[0], nil
+}
+
 func (m *Manager) GetPilotsFromOrg(ctx context.Context, org string) ([]model.Pilot, error) {
 	rows, err := m.client.Query(ctx, "SELECT id, name, home, orgs, tracker_type FROM pilot WHERE $1=ANY(orgs)", org)
 	if err != nil {
@@ -179,14 +248,24 @@ func (m *Manager) GetPilotsFromOrg(ctx context.Context, org string) ([]model.Pil
 	return pilots, nil
 }
 
+// WriteTrack stores the points of the pilot, flagged as outliers against the last valid point already stored.
 func (m *Manager) WriteTrack(ctx context.Context, pilotID string, track []model.Point) error {
 	m.logger.Debug("Inserting track", "pilot", pilotID, "track", track)
 
+	track, err := m.flagOutliers(ctx, pilotID, track)
+	if err != nil {
+		return err
+	}
+
 	for _, point := range track {
-		_, err := m.client.Exec(
+		_, err = m.client.Exec(
 			ctx,
-			`INSERT INTO track (pilot_id, unix_time, latitude, longitude, altitude, msg_type, msg_content)
-			 VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+			`INSERT INTO track (
+				pilot_id, unix_time, latitude, longitude, altitude, msg_type, msg_content,
+				device_model, messenger_name, imei, battery_state, hidden, valid_gps_fix,
+				velocity, course, emergency, outlier
+			 )
+			 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)`,
 			pilotID,
 			point.DateTime,
 			point.Latitude,
@@ -194,13 +273,27 @@ func (m *Manager) WriteTrack(ctx context.Context, pilotID string, track []model.
 			point.Altitude,
 			point.MsgType,
 			point.MsgContent,
+			point.DeviceModel,
+			point.MessengerName,
+			point.IMEI,
+			point.BatteryState,
+			point.Hidden,
+			point.ValidGPSFix,
+			point.Velocity,
+			point.Course,
+			point.Emergency,
+			point.Outlier,
 		)
-		if err, ok := err.(*pq.Error); ok {
-			m.logger.Error("Error writing track", "pilotID", pilotID, "error", err.Code)
-
-			if err.Code != errDuplicateKey {
-				return fmt.Errorf("writing track: %w", err)
+		if err != nil {
+			// The points already stored are skipped, the trackers return the points of the last days.
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) && pgErr.Code == errDuplicateKey {
+				continue
 			}
+
+			m.logger.Error("Error writing track", "pilotID", pilotID, "error", err)
+
+			return fmt.Errorf("writing track: %w", err)
 		}
 	}
 
@@ -210,10 +303,71 @@ func (m *Manager) WriteTrack(ctx context.Context, pilotID string, track []model.
 	return nil
 }
 
-// GetAllTracksOfDay returns all the tracks of the day.
+// flagOutliers returns the points sorted by time and flagged as outliers, the first point is checked against the
+// last valid point stored before it.
+func (m *Manager) flagOutliers(ctx context.Context, pilotID string, track []model.Point) ([]model.Point, error) {
+	if len(track) == 0 {
+		return track, nil
+	}
+
+	sorted := slices.Clone(track)
+	slices.SortStableFunc(sorted, func(a, b model.Point) int {
+		return a.DateTime.Compare(b.DateTime)
+	})
+
+	rows, err := m.client.Query(
+		ctx,
+		`SELECT unix_time, latitude, longitude, altitude, msg_type, msg_content, emergency, outlier
+		 FROM track
+		 WHERE pilot_id = $1 AND unix_time < $2 AND outlier = '' AND NOT emergency
+		 ORDER BY unix_time DESC
+		 LIMIT 1`,
+		pilotID,
+		sorted[0].DateTime,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("querying last valid point: %w", err)
+	}
+
+	previous, err := pgx.CollectRows(rows, pgx.RowToStructByNameLax[model.Point])
+	if err != nil {
+		return nil, fmt.Errorf("collecting last valid point: %w", err)
+	}
+
+	return model.FlagOutliers(append(previous, sorted...), m.filter)[len(previous):], nil
+}
+
+// GetLastPointTime returns the time of the last point stored for the pilot.
+//
+// If the pilot has no point, the zero time is returned.
+func (m *Manager) GetLastPointTime(ctx context.Context, pilotID string) (time.Time, error) {
+	var lastPoint *time.Time
+
+	if err := m.client.QueryRow(
+		ctx,
+		"SELECT MAX(unix_time) FROM track WHERE pilot_id = $1",
+		pilotID,
+	).Scan(&lastPoint); err != nil {
+		return time.Time{}, fmt.Errorf("querying last point time: %w", err)
+	}
+
+	if lastPoint == nil {
+		return time.Time{}, nil
+	}
+
+	m.logger.Debug("Last point time retrieved", "pilot", pilotID, "time", *lastPoint)
+
+	return *lastPoint, nil
+}
+
+// GetAllTracksOfDay returns all the tracks of the day from the source.
 //
 // The key of the map returned is the name of the pilot.
-func (m *Manager) GetAllTracksOfDay(ctx context.Context, date time.Time) (map[string][]model.Point, error) {
+func (m *Manager) GetAllTracksOfDay(
+	ctx context.Context,
+	date time.Time,
+	source TrackSource,
+) (map[string][]model.Point, error) {
 	tracks := make(map[string][]model.Point)
 
 	pilots, err := m.GetAllPilots(ctx)
@@ -222,7 +376,7 @@ func (m *Manager) GetAllTracksOfDay(ctx context.Context, date time.Time) (map[st
 	}
 
 	for _, pilot := range pilots {
-		points, err := m.GetTrackOfDay(ctx, pilot.ID, date)
+		points, err := m.GetTrackOfDay(ctx, pilot.ID, date, source)
 		if err != nil {
 			return nil, fmt.Errorf("getting track of day: %w", err)
 		}
@@ -233,17 +387,25 @@ func (m *Manager) GetAllTracksOfDay(ctx context.Context, date time.Time) (map[st
 	return tracks, nil
 }
 
-// GetTrackOfDay returns the track of the pilot for the given day.
-func (m *Manager) GetTrackOfDay(ctx context.Context, pilotID string, date time.Time) ([]model.Point, error) {
+// GetTrackOfDay returns the track of the pilot for the given day, either the live or the uploaded one.
+func (m *Manager) GetTrackOfDay(
+	ctx context.Context,
+	pilotID string,
+	date time.Time,
+	source TrackSource,
+) ([]model.Point, error) {
 	day := date.Format("2006-01-02")
-	m.logger.Debug("Retrieving track", "pilot", pilotID, "day", day)
+	m.logger.Debug("Retrieving track", "pilot", pilotID, "day", day, "source", source)
 
 	rows, err := m.client.Query(
 		ctx,
-		`SELECT unix_time, latitude, longitude, altitude, msg_type, msg_content
-		 FROM track
+		//nolint:gosec // The table is one of the constants of the sources.
+		fmt.Sprintf(`SELECT unix_time, latitude, longitude, altitude, msg_type, msg_content,
+		        device_model, messenger_name, imei, battery_state, hidden, valid_gps_fix,
+		        velocity, course, emergency, outlier
+		 FROM %s
 		 WHERE pilot_id = $1 AND DATE(unix_time) = $2
-		 ORDER BY unix_time`,
+		 ORDER BY unix_time`, source.table()),
 		pilotID,
 		day,
 	)
@@ -275,7 +437,9 @@ func (m *Manager) GetTrackSince(ctx context.Context, pilotID string, since time.
 
 	rows, err := m.client.Query(
 		ctx,
-		`SELECT unix_time, latitude, longitude, altitude, msg_type, msg_content
+		`SELECT unix_time, latitude, longitude, altitude, msg_type, msg_content,
+		        device_model, messenger_name, imei, battery_state, hidden, valid_gps_fix,
+		        velocity, course, emergency, outlier
 		 FROM track
 		 WHERE pilot_id = $1 AND unix_time > $2
 		 ORDER BY unix_time`,
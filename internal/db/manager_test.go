@@ -125,6 +125,8 @@ func TestManager_GetAllPilots(t *testing.T) {
 	assert.Len(t, pilots, 4)
 	assert.Equal(t, "Bix", pilots[0].Name)
 	assert.Equal(t, "Ferrix", pilots[0].Home)
+	assert.Empty(t, pilots[0].FeedPassword)
+	assert.Equal(t, model.Secret("axis"), pilots[2].FeedPassword)
 }
 
 func TestManager_GetPilotID(t *testing.T) {
@@ -136,6 +138,43 @@ func TestManager_GetPilotID(t *testing.T) {
 	assert.Equal(t, "0RKUQmnYcUhGflhlrrsm9jthBJo2WjNOq", pilotID)
 }
 
+func TestManager_GetPilot(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	pilot, err := manager.GetPilot(ctx, "0RKUQmnYcUhGflhlrrsm9jthBJo2WjNOq")
+	require.NoError(t, err)
+	assert.Equal(t, "Luthen", pilot.Name)
+
+	_, err = manager.GetPilot(ctx, "unknown")
+	require.ErrorIs(t, err, db.ErrPilotNotFound)
+}
+
+func TestManager_GetPilotIDByToken(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	pilotID, err := manager.GetPilotIDByToken(ctx, "gideon")
+	require.NoError(t, err)
+	assert.Equal(t, "0Sqp9zyH3ZOfaWhPi4KeUd2GNfqTW43aG", pilotID)
+
+	_, err = manager.GetPilotIDByToken(ctx, "unknown")
+	require.ErrorIs(t, err, db.ErrPilotNotFound)
+}
+
+func TestManager_GetPilotIDByUploadToken(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	pilotID, err := manager.GetPilotIDByUploadToken(ctx, "andor")
+	require.NoError(t, err)
+	assert.Equal(t, "0Z7eRKM9rCcrima9ic2qqvNFjDjgf87fG", pilotID)
+
+	// The ingest token of the apps does not allow to upload.
+	_, err = manager.GetPilotIDByUploadToken(ctx, "gideon")
+	require.ErrorIs(t, err, db.ErrPilotNotFound)
+}
+
 func TestManager_GetPilotsFromOrg(t *testing.T) {
 	t.Parallel()
 
@@ -160,12 +199,18 @@ func TestManager_WriteTrack(t *testing.T) {
 			MsgContent: "",
 		},
 		{
-			DateTime:   time.Date(2023, time.Month(8), 22, 8, 5, 0, 0, time.UTC),
-			Latitude:   46.45549,
-			Longitude:  6.8854,
-			Altitude:   0,
-			MsgType:    "OK",
-			MsgContent: "Pilot has landed safely",
+			DateTime:     time.Date(2023, time.Month(8), 22, 8, 5, 0, 0, time.UTC),
+			Latitude:     46.45549,
+			Longitude:    6.8854,
+			Altitude:     0,
+			MsgType:      "OK",
+			MsgContent:   "Pilot has landed safely",
+			DeviceModel:  "SPOT3",
+			BatteryState: model.BatteryLow,
+			ValidGPSFix:  true,
+			Velocity:     12.5,
+			Course:       45,
+			Emergency:    true,
 		},
 	}
 	err := manager.WriteTrack(ctx, "0Z7eRKM9rCcrima9ic2qqvNFjDjgf87fG", points)
@@ -176,12 +221,17 @@ func TestManager_WriteTrack(t *testing.T) {
 	require.NoError(t, err)
 
 	// Retrieve the track of the given day.
-	pointsA, err := manager.GetTrackOfDay(ctx, "0Z7eRKM9rCcrima9ic2qqvNFjDjgf87fG", time.Date(2023, time.Month(8), 22, 0, 0, 0, 0, time.UTC))
+	pointsA, err := manager.GetTrackOfDay(ctx, "0Z7eRKM9rCcrima9ic2qqvNFjDjgf87fG", time.Date(2023, time.Month(8), 22, 0, 0, 0, 0, time.UTC), db.SourceLive)
 	require.NoError(t, err)
 	assert.Len(t, pointsA, 2)
+	assert.Equal(t, "SPOT3", pointsA[1].DeviceModel)
+	assert.True(t, pointsA[1].IsBatteryLow())
+	assert.True(t, pointsA[1].ValidGPSFix)
+	assert.InDelta(t, 12.5, pointsA[1].Velocity, 0.01)
+	assert.True(t, pointsA[1].IsEmergency())
 
 	// Retrieve when no track.
-	pointsB, err := manager.GetTrackOfDay(ctx, "0RKUQmnYcUhGflhlrrsm9jthBJo2WjNOq", time.Date(2023, time.Month(8), 23, 0, 0, 0, 0, time.UTC))
+	pointsB, err := manager.GetTrackOfDay(ctx, "0RKUQmnYcUhGflhlrrsm9jthBJo2WjNOq", time.Date(2023, time.Month(8), 23, 0, 0, 0, 0, time.UTC), db.SourceLive)
 	require.NoError(t, err)
 	assert.Empty(t, pointsB)
 
@@ -189,4 +239,171 @@ func TestManager_WriteTrack(t *testing.T) {
 	pointsC, err := manager.GetTrackSince(ctx, "0Z7eRKM9rCcrima9ic2qqvNFjDjgf87fG", time.Date(2023, time.Month(8), 22, 8, 0, 0, 0, time.UTC))
 	require.NoError(t, err)
 	assert.Len(t, pointsC, 1)
+
+	// Retrieve the time of the last point.
+	lastPoint, err := manager.GetLastPointTime(ctx, "0Z7eRKM9rCcrima9ic2qqvNFjDjgf87fG")
+	require.NoError(t, err)
+	assert.True(t, time.Date(2023, time.Month(8), 22, 8, 5, 0, 0, time.UTC).Equal(lastPoint))
+
+	// Retrieve the time of the last point when no track.
+	lastPoint, err = manager.GetLastPointTime(ctx, "0RKUQmnYcUhGflhlrrsm9jthBJo2WjNOq")
+	require.NoError(t, err)
+	assert.True(t, lastPoint.IsZero())
+}
+
+func TestManager_WriteTrack_Outliers(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	pilotID := "0D3D3Gdn4JqV4hEkp4TRiRoc02Hk5frJa"
+	day := time.Date(2023, time.Month(8), 27, 0, 0, 0, 0, time.UTC)
+	err := manager.WriteTrack(ctx, pilotID, []model.Point{
+		{DateTime: day.Add(10 * time.Hour), Latitude: 46.62516, Longitude: 7.20616, Altitude: 1604, MsgType: model.MsgTypeTrack},
+		{DateTime: day.Add(10*time.Hour + time.Minute), Latitude: 46.6262, Longitude: 7.207, Altitude: 1650, MsgType: model.MsgTypeTrack},
+	})
+	require.NoError(t, err)
+
+	// The first point of the next write is checked against the last valid point stored.
+	err = manager.WriteTrack(ctx, pilotID, []model.Point{
+		{DateTime: day.Add(10*time.Hour + 2*time.Minute), Latitude: 47.5, Longitude: 8.5, Altitude: 1680, MsgType: model.MsgTypeTrack},
+		{DateTime: day.Add(10*time.Hour + 3*time.Minute), Latitude: 46.627, Longitude: 7.2078, Altitude: 1700, MsgType: model.MsgTypeTrack},
+	})
+	require.NoError(t, err)
+
+	points, err := manager.GetTrackOfDay(ctx, pilotID, day, db.SourceLive)
+	require.NoError(t, err)
+	require.Len(t, points, 4)
+
+	outliers := make([]model.Outlier, 0, len(points))
+	for _, point := range points {
+		outliers = append(outliers, point.Outlier)
+	}
+
+	assert.Equal(t, []model.Outlier{"", "", model.OutlierSpeedJump, ""}, outliers)
+}
+
+func TestManager_WriteUploadedTrack(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	pilotID := "0D3D3Gdn4JqV4hEkp4TRiRoc02Hk5frJa"
+	day := time.Date(2023, time.Month(8), 25, 0, 0, 0, 0, time.UTC)
+	points := []model.Point{
+		{DateTime: day.Add(10 * time.Hour), Latitude: 46.62516, Longitude: 7.20616, Altitude: 1604, MsgType: model.MsgTypeTrack},
+		{DateTime: day.Add(10*time.Hour + time.Second), Latitude: 46.6252, Longitude: 7.20625, Altitude: 1607, MsgType: model.MsgTypeTrack},
+		{DateTime: day.Add(10*time.Hour + 2*time.Second), Latitude: 46.62525, Longitude: 7.20635, Altitude: 1610, MsgType: model.MsgTypeTrack},
+	}
+	err := manager.WriteUploadedTrack(ctx, pilotID, points)
+	require.NoError(t, err)
+
+	// Uploading again replaces the points of the period.
+	err = manager.WriteUploadedTrack(ctx, pilotID, points[:2])
+	require.NoError(t, err)
+
+	uploaded, err := manager.GetTrackOfDay(ctx, pilotID, day, db.SourceUploaded)
+	require.NoError(t, err)
+	require.Len(t, uploaded, 3)
+	assert.Equal(t, 1607, uploaded[1].Altitude)
+
+	// The uploaded points are not part of the live track.
+	live, err := manager.GetTrackOfDay(ctx, pilotID, day, db.SourceLive)
+	require.NoError(t, err)
+	assert.Empty(t, live)
+
+	tracks, err := manager.GetAllTracksOfDay(ctx, day, db.SourceUploaded)
+	require.NoError(t, err)
+	assert.Len(t, tracks["Bix"], 3)
+}
+
+func TestManager_StreamTracks(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	day := time.Date(2023, time.Month(8), 26, 0, 0, 0, 0, time.UTC)
+	points := []model.Point{
+		{DateTime: day.Add(9 * time.Hour), Latitude: 46.45669, Longitude: 6.88411, Altitude: 479, MsgType: model.MsgTypeTrack},
+		{DateTime: day.Add(9*time.Hour + 5*time.Minute), Latitude: 46.45549, Longitude: 6.8854, Altitude: 512, MsgType: model.MsgTypeTrack},
+		{DateTime: day.Add(33 * time.Hour), Latitude: 46.45549, Longitude: 6.8854, Altitude: 512, MsgType: model.MsgTypeOK},
+	}
+	err := manager.WriteTrack(ctx, "0Sqp9zyH3ZOfaWhPi4KeUd2GNfqTW43aG", points)
+	require.NoError(t, err)
+
+	var (
+		pilots []string
+		counts []int
+	)
+
+	filter := db.ExportFilter{From: day, To: day.AddDate(0, 0, 1), Org: "empire", Source: db.SourceLive}
+	err = manager.StreamTracks(ctx, filter, func(pilot string, track []model.Point) error {
+		pilots = append(pilots, pilot)
+		counts = append(counts, len(track))
+
+		// The statistics are computed by track.
+		assert.Zero(t, track[0].CumDist)
+
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Moff", "Moff"}, pilots)
+	assert.Equal(t, []int{2, 1}, counts)
+
+	// No track for the pilots of another org.
+	filter.Org = "unknown"
+	err = manager.StreamTracks(ctx, filter, func(string, []model.Point) error {
+		t.Fatal("unexpected track")
+
+		return nil
+	})
+	require.NoError(t, err)
+}
+
+func TestManager_WritePayload(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	payload := model.Payload{
+		PilotID:    "0D3D3Gdn4JqV4hEkp4TRiRoc02Hk5frJa",
+		Source:     "spot",
+		StatusCode: 200,
+		FetchedAt:  time.Date(2023, time.Month(8), 24, 8, 0, 0, 0, time.UTC),
+		Content:    []byte(`{"response":{}}`),
+	}
+	err := manager.WritePayload(ctx, payload)
+	require.NoError(t, err)
+
+	// Retrieve the payloads of the day, decompressed.
+	from, to := time.Date(2023, time.Month(8), 24, 0, 0, 0, 0, time.UTC), time.Date(2023, time.Month(8), 25, 0, 0, 0, 0, time.UTC)
+	payloads := collectPayloads(t, "spot", "", from, to)
+	require.Len(t, payloads, 1)
+	assert.Equal(t, payload.Content, payloads[0].Content)
+	assert.Equal(t, payload.PilotID, payloads[0].PilotID)
+
+	// Retrieve the payloads of the pilot.
+	payloads = collectPayloads(t, "", payload.PilotID, from, to)
+	assert.Len(t, payloads, 1)
+
+	// Retrieve the payloads of another source or pilot.
+	assert.Empty(t, collectPayloads(t, "garmin", "", from, to))
+	assert.Empty(t, collectPayloads(t, "", "unknown", from, to))
+
+	// Remove the old payloads.
+	count, err := manager.DeletePayloadsBefore(ctx, time.Date(2023, time.Month(8), 25, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+}
+
+// collectPayloads returns the archived payloads of the source and pilot between from and to.
+func collectPayloads(t *testing.T, source, pilotID string, from, to time.Time) []model.Payload {
+	t.Helper()
+
+	var payloads []model.Payload
+
+	err := manager.ForEachPayload(t.Context(), source, pilotID, from, to, func(payload model.Payload) error {
+		payloads = append(payloads, payload)
+
+		return nil
+	})
+	require.NoError(t, err)
+
+	return payloads
 }
@@ -0,0 +1,128 @@
+package db
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"fahy.xyz/livetrack/internal/model"
+	"github.com/jackc/pgx/v5"
+)
+
+// WritePayload archives the raw payload of a source, compressed with gzip.
+func (m *Manager) WritePayload(ctx context.Context, payload model.Payload) error {
+	var buffer bytes.Buffer
+
+	writer := gzip.NewWriter(&buffer)
+	if _, err := writer.Write(payload.Content); err != nil {
+		return fmt.Errorf("compressing payload: %w", err)
+	}
+
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("closing compressed payload: %w", err)
+	}
+
+	if _, err := m.client.Exec(
+		ctx,
+		`INSERT INTO payload (pilot_id, source, status_code, fetched_at, content)
+		 VALUES ($1, $2, $3, $4, $5)`,
+		payload.PilotID,
+		payload.Source,
+		payload.StatusCode,
+		payload.FetchedAt,
+		buffer.Bytes(),
+	); err != nil {
+		return fmt.Errorf("writing payload: %w", err)
+	}
+
+	m.logger.Debug("Payload written", "pilotID", payload.PilotID, "source", payload.Source, "size", buffer.Len())
+
+	return nil
+}
+
+// ForEachPayload calls fn with each archived payload fetched between from and to, ordered by time.
+//
+// If the source or the pilot ID is empty, the payloads of all the sources or pilots are read. The payloads are
+// decompressed one at a time, so a range of days is not loaded in memory. An error of fn stops the iteration.
+func (m *Manager) ForEachPayload(
+	ctx context.Context,
+	source, pilotID string,
+	from, to time.Time,
+	fn func(model.Payload) error,
+) error {
+	rows, err := m.client.Query(
+		ctx,
+		`SELECT id, pilot_id, source, status_code, fetched_at, content
+		 FROM payload
+		 WHERE ($1 = '' OR source = $1) AND ($2 = '' OR pilot_id = $2) AND fetched_at >= $3 AND fetched_at < $4
+		 ORDER BY fetched_at`,
+		source,
+		pilotID,
+		from,
+		to,
+	)
+	if err != nil {
+		return fmt.Errorf("querying payloads: %w", err)
+	}
+
+	defer rows.Close()
+
+	var count int
+
+	for rows.Next() {
+		payload, err := pgx.RowToStructByName[model.Payload](rows)
+		if err != nil {
+			return fmt.Errorf("scanning payload: %w", err)
+		}
+
+		if payload.Content, err = decompress(payload.Content); err != nil {
+			return fmt.Errorf("decompressing payload %d: %w", payload.ID, err)
+		}
+
+		if err = fn(payload); err != nil {
+			return err
+		}
+
+		count++
+	}
+
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("reading payloads: %w", err)
+	}
+
+	m.logger.Debug("Payloads retrieved", "source", source, "pilotID", pilotID, "from", from, "to", to, "count", count)
+
+	return nil
+}
+
+// DeletePayloadsBefore removes the payloads fetched before the given time.
+func (m *Manager) DeletePayloadsBefore(ctx context.Context, before time.Time) (int64, error) {
+	tag, err := m.client.Exec(ctx, "DELETE FROM payload WHERE fetched_at < $1", before)
+	if err != nil {
+		return 0, fmt.Errorf("deleting payloads: %w", err)
+	}
+
+	m.logger.Debug("Payloads deleted", "before", before, "count", tag.RowsAffected())
+
+	return tag.RowsAffected(), nil
+}
+
+// decompress returns the content of a payload compressed with gzip.
+func decompress(content []byte) ([]byte, error) {
+	reader, err := gzip.NewReader(bytes.NewReader(content))
+	if err != nil {
+		return nil, fmt.Errorf("reading compressed content: %w", err)
+	}
+
+	defer reader.Close()
+
+	decompressed, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("reading content: %w", err)
+	}
+
+	return decompressed, nil
+}
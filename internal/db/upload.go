@@ -0,0 +1,109 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"fahy.xyz/livetrack/internal/model"
+	"github.com/jackc/pgx/v5"
+)
+
+// TrackSource is the origin of the points of a track.
+type TrackSource string
+
+const (
+	// SourceLive are the points sent by the trackers during the flight.
+	SourceLive TrackSource = "live"
+	// SourceUploaded are the points of the flight logs uploaded after the flight.
+	SourceUploaded TrackSource = "uploaded"
+)
+
+var ErrUnknownSource = errors.New("unknown track source")
+
+// ParseTrackSource returns the track source of its name, the live source if empty.
+func ParseTrackSource(name string) (TrackSource, error) {
+	switch TrackSource(name) {
+	case "", SourceLive:
+		return SourceLive, nil
+	case SourceUploaded:
+		return SourceUploaded, nil
+	default:
+		return "", fmt.Errorf("%w: %s", ErrUnknownSource, name)
+	}
+}
+
+// table returns the table storing the points of the source.
+func (s TrackSource) table() string {
+	if s == SourceUploaded {
+		return "uploaded_track"
+	}
+
+	return "track"
+}
+
+// WriteUploadedTrack stores the points of a flight log uploaded by the pilot.
+//
+// The points must be sorted by time. They replace the points previously uploaded over the same period,
+// so a flight log can be uploaded again, and are flagged as outliers over the whole flight log.
+func (m *Manager) WriteUploadedTrack(ctx context.Context, pilotID string, track []model.Point) error {
+	if len(track) == 0 {
+		return nil
+	}
+
+	tx, err := m.client.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("beginning transaction: %w", err)
+	}
+
+	defer tx.Rollback(ctx) //nolint:errcheck // No-op once committed.
+
+	if _, err = tx.Exec(
+		ctx,
+		"DELETE FROM uploaded_track WHERE pilot_id = $1 AND unix_time BETWEEN $2 AND $3",
+		pilotID,
+		track[0].DateTime,
+		track[len(track)-1].DateTime,
+	); err != nil {
+		return fmt.Errorf("deleting previous upload: %w", err)
+	}
+
+	rows := make([][]any, 0, len(track))
+	for _, point := range model.FlagOutliers(track, m.filter) {
+		rows = append(rows, []any{
+			pilotID,
+			point.DateTime,
+			point.Latitude,
+			point.Longitude,
+			point.Altitude,
+			point.MsgType,
+			point.MsgContent,
+			point.ValidGPSFix,
+			point.Velocity,
+			point.Course,
+			point.Outlier,
+		})
+	}
+
+	// The flight logs have a point by second, they are copied in bulk.
+	if _, err = tx.CopyFrom(
+		ctx,
+		pgx.Identifier{"uploaded_track"},
+		[]string{
+			"pilot_id", "unix_time", "latitude", "longitude", "altitude", "msg_type", "msg_content",
+			"valid_gps_fix", "velocity", "course", "outlier",
+		},
+		pgx.CopyFromRows(rows),
+	); err != nil {
+		return fmt.Errorf("copying uploaded track: %w", err)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("committing uploaded track: %w", err)
+	}
+
+	m.logger.Debug("Uploaded track written", "pilotID", pilotID, "points", len(track))
+	m.metrics.TrackWritten()
+
+	return nil
+}
@@ -0,0 +1,149 @@
+package fetcher
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// CircuitState is the state of a circuit breaker.
+type CircuitState int
+
+const (
+	// CircuitClosed lets all the requests through.
+	CircuitClosed CircuitState = iota
+	// CircuitHalfOpen lets a single request through to probe the source.
+	CircuitHalfOpen
+	// CircuitOpen rejects all the requests until the cooldown is over.
+	CircuitOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitHalfOpen:
+		return "half-open"
+	case CircuitOpen:
+		return "open"
+	default:
+		return "unknown"
+	}
+}
+
+// circuitBreaker pauses the requests to a source after consecutive failures.
+//
+// Once open, the requests are rejected until the cooldown is over. Then a single
+// request is let through: the circuit is closed if it succeeds, or open again otherwise.
+type circuitBreaker struct {
+	threshold int
+	cooldown  time.Duration
+	onChange  func(state CircuitState)
+	now       func() time.Time
+
+	mu        sync.Mutex
+	state     CircuitState
+	failures  int
+	openUntil time.Time
+	probing   bool
+}
+
+func newCircuitBreaker(threshold int, cooldown time.Duration, onChange func(state CircuitState)) *circuitBreaker {
+	return &circuitBreaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		onChange:  onChange,
+		now:       time.Now,
+		state:     CircuitClosed,
+	}
+}
+
+// Allow returns ErrCircuitOpen if the request must not be sent.
+func (b *circuitBreaker) Allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == CircuitOpen {
+		if b.now().Before(b.openUntil) {
+			return ErrCircuitOpen
+		}
+
+		b.setState(CircuitHalfOpen)
+	}
+
+	if b.state == CircuitHalfOpen {
+		if b.probing {
+			return ErrCircuitOpen
+		}
+
+		b.probing = true
+	}
+
+	return nil
+}
+
+// Success records a successful request and closes the circuit.
+func (b *circuitBreaker) Success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures = 0
+	b.probing = false
+	b.setState(CircuitClosed)
+}
+
+// Failure records a failed request and opens the circuit if the threshold is reached.
+func (b *circuitBreaker) Failure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	b.probing = false
+
+	if b.state == CircuitHalfOpen || b.failures >= b.threshold {
+		b.open(b.cooldown)
+	}
+}
+
+// Abort records a request cancelled before the source answered.
+func (b *circuitBreaker) Abort() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+}
+
+// Trip opens the circuit for the given duration, e.g. when the source asks to retry later.
+func (b *circuitBreaker) Trip(duration time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+	b.open(duration)
+}
+
+// State returns the current state of the circuit.
+func (b *circuitBreaker) State() CircuitState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.state
+}
+
+func (b *circuitBreaker) open(duration time.Duration) {
+	b.openUntil = b.now().Add(duration)
+	b.setState(CircuitOpen)
+}
+
+func (b *circuitBreaker) setState(state CircuitState) {
+	if b.state == state {
+		return
+	}
+
+	b.state = state
+	if b.onChange != nil {
+		b.onChange(state)
+	}
+}
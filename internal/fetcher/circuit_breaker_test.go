@@ -0,0 +1,55 @@
+package fetcher
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2023, time.Month(8), 23, 12, 0, 0, 0, time.UTC)
+	states := []CircuitState{}
+
+	breaker := newCircuitBreaker(2, time.Minute, func(state CircuitState) {
+		states = append(states, state)
+	})
+	breaker.now = func() time.Time { return now }
+
+	// The circuit opens after the threshold of consecutive failures.
+	require.NoError(t, breaker.Allow())
+	breaker.Failure()
+	assert.Equal(t, CircuitClosed, breaker.State())
+	require.NoError(t, breaker.Allow())
+	breaker.Failure()
+	assert.Equal(t, CircuitOpen, breaker.State())
+	require.ErrorIs(t, breaker.Allow(), ErrCircuitOpen)
+
+	// After the cooldown, a single request is let through.
+	now = now.Add(time.Minute)
+	require.NoError(t, breaker.Allow())
+	assert.Equal(t, CircuitHalfOpen, breaker.State())
+	require.ErrorIs(t, breaker.Allow(), ErrCircuitOpen)
+
+	// A failure in half-open opens the circuit again.
+	breaker.Failure()
+	assert.Equal(t, CircuitOpen, breaker.State())
+
+	// A success in half-open closes the circuit.
+	now = now.Add(time.Minute)
+	require.NoError(t, breaker.Allow())
+	breaker.Success()
+	assert.Equal(t, CircuitClosed, breaker.State())
+
+	// Tripping opens the circuit for the given duration.
+	breaker.Trip(time.Hour)
+	now = now.Add(time.Minute)
+	require.ErrorIs(t, breaker.Allow(), ErrCircuitOpen)
+
+	assert.Equal(t, []CircuitState{
+		CircuitOpen, CircuitHalfOpen, CircuitOpen, CircuitHalfOpen, CircuitClosed, CircuitOpen,
+	}, states)
+}
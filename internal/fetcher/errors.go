@@ -0,0 +1,60 @@
+package fetcher
+
+import (
+	"errors"
+	"net/http"
+)
+
+var (
+	ErrFeedNotFound     = errors.New("feed not found")
+	ErrFeedPrivate      = errors.New("feed is private")
+	ErrRateLimited      = errors.New("rate limited by the source")
+	ErrUpstreamDown     = errors.New("source is down")
+	ErrMalformedPayload = errors.New("malformed payload")
+	ErrUnexpectedStatus = errors.New("unexpected status code")
+)
+
+// statusError returns the error matching the status code of a response, nil if successful.
+func statusError(statusCode int) error {
+	switch {
+	case statusCode >= http.StatusOK && statusCode < http.StatusMultipleChoices:
+		return nil
+	case statusCode == http.StatusNotFound:
+		return ErrFeedNotFound
+	case statusCode == http.StatusUnauthorized, statusCode == http.StatusForbidden:
+		return ErrFeedPrivate
+	case statusCode == http.StatusTooManyRequests:
+		return ErrRateLimited
+	case statusCode >= http.StatusInternalServerError:
+		return ErrUpstreamDown
+	default:
+		return ErrUnexpectedStatus
+	}
+}
+
+// isTransient returns true if the request can be retried.
+func isTransient(err error) bool {
+	return errors.Is(err, ErrRateLimited) || errors.Is(err, ErrUpstreamDown)
+}
+
+// ErrorKind returns the kind of a fetch error, used as label in the metrics.
+func ErrorKind(err error) string {
+	switch {
+	case errors.Is(err, ErrFeedNotFound):
+		return "not_found"
+	case errors.Is(err, ErrFeedPrivate):
+		return "private"
+	case errors.Is(err, ErrRateLimited):
+		return "rate_limited"
+	case errors.Is(err, ErrUpstreamDown):
+		return "upstream_down"
+	case errors.Is(err, ErrMalformedPayload):
+		return "malformed_payload"
+	case errors.Is(err, ErrUnexpectedStatus):
+		return "unexpected_status"
+	case errors.Is(err, ErrCircuitOpen):
+		return "circuit_open"
+	default:
+		return "unknown"
+	}
+}
@@ -1,23 +1,70 @@
 package fetcher
 
 import (
+	"context"
 	"time"
 
 	"fahy.xyz/livetrack/internal/model"
 )
 
+// Fetcher retrieves the points of a tracker source.
 type Fetcher interface {
-	Fetch(id string) ([]model.Point, error)
+	// Fetch returns the points of the pilot's tracker sent after since.
+	Fetch(ctx context.Context, pilot model.Pilot, since time.Time) ([]model.Point, error)
+	// TrackerType returns the tracker type handled, matching the pilot's tracker type.
+	TrackerType() string
+}
+
+// Parser converts the raw payload of a source into points.
+//
+// It is used to parse again the archived payloads.
+type Parser interface {
+	ParsePayload(body []byte) ([]model.Point, error)
+}
+
+// Archive stores the raw payloads of the sources.
+type Archive interface {
+	WritePayload(ctx context.Context, payload model.Payload) error
 }
 
 type metrics interface {
 	MessageFetched(source string)
+	RequestRetried(source string)
+	CircuitStateChanged(source string, state int)
+	FetchFailed(source, kind string)
 }
 
 type emptyMetrics struct{}
 
-func (m emptyMetrics) MessageFetched(string) {}
+func (m emptyMetrics) MessageFetched(string)           {}
+func (m emptyMetrics) RequestRetried(string)           {}
+func (m emptyMetrics) CircuitStateChanged(string, int) {}
+func (m emptyMetrics) FetchFailed(string, string)      {}
 
 const (
 	HTTPTimeout = 10 * time.Second
 )
+
+// OsmAndTracker is the tracker type of the pilots pushing their positions with the OsmAnd protocol.
+const OsmAndTracker = "osmand"
+
+// IsPushTracker returns true if the positions of the tracker type are pushed to us instead of fetched.
+func IsPushTracker(trackerType string) bool {
+	return trackerType == OGNTracker || trackerType == OsmAndTracker || trackerType == MeshtasticTracker
+}
+
+// StartTime returns the time from which the points of a tracker must be fetched.
+//
+// The fetch starts from the last stored point minus the lookback window, to catch
+// satellite messages arriving late, but never before the start of the current day.
+func StartTime(lastPoint, now time.Time, lookback time.Duration) time.Time {
+	year, month, day := now.UTC().Date()
+	startOfDay := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+
+	since := lastPoint.Add(-lookback)
+	if since.Before(startOfDay) {
+		return startOfDay
+	}
+
+	return since.UTC()
+}
@@ -0,0 +1,60 @@
+package fetcher
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStartTime(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2023, time.Month(8), 23, 12, 0, 0, 0, time.UTC)
+	startOfDay := time.Date(2023, time.Month(8), 23, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		lastPoint time.Time
+		expected  time.Time
+	}{
+		{
+			name:      "no point stored",
+			lastPoint: time.Time{},
+			expected:  startOfDay,
+		},
+		{
+			name:      "last point on a previous day",
+			lastPoint: time.Date(2023, time.Month(8), 22, 18, 0, 0, 0, time.UTC),
+			expected:  startOfDay,
+		},
+		{
+			name:      "last point in the lookback window of the day",
+			lastPoint: time.Date(2023, time.Month(8), 23, 0, 10, 0, 0, time.UTC),
+			expected:  startOfDay,
+		},
+		{
+			name:      "last point of the day",
+			lastPoint: time.Date(2023, time.Month(8), 23, 11, 30, 0, 0, time.UTC),
+			expected:  time.Date(2023, time.Month(8), 23, 11, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.expected, StartTime(tt.lastPoint, now, 30*time.Minute))
+		})
+	}
+}
+
+func TestIsPushTracker(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, IsPushTracker(OGNTracker))
+	assert.True(t, IsPushTracker(OsmAndTracker))
+	assert.True(t, IsPushTracker(MeshtasticTracker))
+	assert.False(t, IsPushTracker(SpotTracker))
+	assert.False(t, IsPushTracker(GarminTracker))
+}
@@ -3,7 +3,6 @@ package fetcher
 import (
 	"context"
 	"fmt"
-	"io"
 	"log/slog"
 	"net/http"
 	"net/url"
@@ -11,79 +10,99 @@ import (
 
 	"fahy.xyz/livetrack/internal/model"
 	"fahy.xyz/livetrack/internal/model/garmin"
+	"golang.org/x/time/rate"
 )
 
+// GarminTracker is the tracker type of the garmin fetcher.
+const GarminTracker = "garmin"
+
 type GarminFetcher struct {
-	client  *http.Client
-	url     string
-	logger  *slog.Logger
-	metrics metrics
+	requester *requester
+	url       string
+	logger    *slog.Logger
+	metrics   metrics
 }
 
 func NewGarminFetcher(url string, logger *slog.Logger, metrics metrics) *GarminFetcher {
 	return &GarminFetcher{
-		client:  &http.Client{Timeout: HTTPTimeout},
-		url:     url,
-		logger:  logger,
-		metrics: metrics,
+		requester: newRequester(GarminTracker, logger, metrics),
+		url:       url,
+		logger:    logger,
+		metrics:   metrics,
 	}
 }
 
-func (f *GarminFetcher) Fetch(ctx context.Context, id string) ([]model.Point, error) {
-	url, err := f.createURL(id)
+// TrackerType returns the tracker type of the garmin fetcher.
+func (f *GarminFetcher) TrackerType() string {
+	return GarminTracker
+}
+
+// Fetch retrieves the points of the pilot's feed since the given time.
+//
+// If the feed is password-protected, the password is sent with basic authentication.
+func (f *GarminFetcher) Fetch(ctx context.Context, pilot model.Pilot, since time.Time) ([]model.Point, error) {
+	url, err := f.createURL(pilot.ID, since)
 	if err != nil {
 		return nil, fmt.Errorf("creating URL: %w", err)
 	}
 
 	f.logger.Info("fetching", "url", url)
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	body, err := f.requester.get(ctx, pilot.ID, url, func(req *http.Request) {
+		if pilot.FeedPassword != "" {
+			// The username is ignored by the feed, only the password is checked.
+			req.SetBasicAuth("", string(pilot.FeedPassword))
+		}
+	})
 	if err != nil {
-		return nil, fmt.Errorf("creating request: %w", err)
+		return nil, fmt.Errorf("requesting feed: %w", err)
 	}
 
-	req.Header.Set("User-Agent", "Wget/1.13.4 (linux-gnu)")
-
-	resp, err := f.client.Do(req)
+	points, err := f.ParsePayload(body)
 	if err != nil {
-		return nil, fmt.Errorf("executing request: %w", err)
+		return nil, err
 	}
 
-	defer resp.Body.Close()
+	f.metrics.MessageFetched(GarminTracker)
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("reading body: %w", err)
-	}
+	return points, nil
+}
 
+// ParsePayload converts a raw KML feed into points.
+func (f *GarminFetcher) ParsePayload(body []byte) ([]model.Point, error) {
 	response, err := garmin.Parse(body)
 	if err != nil {
-		return nil, fmt.Errorf("parsing body: %w", err)
+		return nil, fmt.Errorf("%w: parsing body: %w", ErrMalformedPayload, err)
 	}
 
 	points, err := response.ToPoints()
 	if err != nil {
-		return nil, fmt.Errorf("parsing points: %w", err)
+		return nil, fmt.Errorf("%w: parsing points: %w", ErrMalformedPayload, err)
 	}
 
-	f.metrics.MessageFetched("garmin")
-
 	return points, nil
 }
 
-func (f *GarminFetcher) createURL(id string) (string, error) {
+// SetArchive stores the raw responses of the feed in the archive.
+func (f *GarminFetcher) SetArchive(archive Archive) {
+	f.requester.archive = archive
+}
+
+// SetRateLimit sets the minimum delay between two requests to the feed, for all the pilots, 0 to not throttle.
+func (f *GarminFetcher) SetRateLimit(interval time.Duration) {
+	f.requester.limiter = rate.NewLimiter(rate.Every(interval), 1)
+}
+
+// createURL returns the feed URL with the points since the given time.
+//
+// Without end date, the feed returns all the points until now.
+func (f *GarminFetcher) createURL(id string, since time.Time) (string, error) {
 	urlWithID, err := url.JoinPath(f.url, id)
 	if err != nil {
 		return "", fmt.Errorf("joining path: %w", err)
 	}
 
-	year, month, day := time.Now().Date()
-	sWithDate := fmt.Sprintf(
-		"%s?d1=%s&d2=%s",
-		urlWithID,
-		time.Date(year, month, day, 0, 0, 0, 0, time.UTC).Format("2006-01-02T15:04"),
-		time.Date(year, month, day, 23, 59, 0, 0, time.UTC).Format("2006-01-02T15:04"),
-	)
+	sWithDate := fmt.Sprintf("%s?d1=%s", urlWithID, since.UTC().Format("2006-01-02T15:04"))
 
 	return sWithDate, nil
 }
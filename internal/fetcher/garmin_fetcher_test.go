@@ -1,7 +1,7 @@
 package fetcher
 
 import (
-	"fmt"
+	"bytes"
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
@@ -9,6 +9,7 @@ import (
 	"testing"
 	"time"
 
+	"fahy.xyz/livetrack/internal/model"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -21,15 +22,14 @@ func TestGarminFetcher_createURL(t *testing.T) {
 		slog.Default().With("component", "garmin-fetcher"),
 		&emptyMetrics{},
 	)
-	urlA, err := fetcherA.createURL("garminId")
+	urlA, err := fetcherA.createURL("garminId", time.Date(2023, time.Month(8), 23, 10, 26, 45, 0, time.UTC))
 	require.NoError(t, err)
+	assert.Equal(t, "https://share.garmin.com/Feed/Share/garminId?d1=2023-08-23T10:26", urlA)
 
-	year, month, day := time.Now().Date()
-	assert.Equal(
-		t,
-		fmt.Sprintf("https://share.garmin.com/Feed/Share/garminId?d1=%s&d2=%s", time.Date(year, month, day, 0, 0, 0, 0, time.UTC).Format("2006-01-02T15:04"), time.Date(year, month, day, 23, 59, 0, 0, time.UTC).Format("2006-01-02T15:04")),
-		urlA,
-	)
+	// The start date is always sent in UTC.
+	urlB, err := fetcherA.createURL("garminId", time.Date(2023, time.Month(8), 23, 12, 26, 45, 0, time.FixedZone("CEST", 2*60*60)))
+	require.NoError(t, err)
+	assert.Equal(t, "https://share.garmin.com/Feed/Share/garminId?d1=2023-08-23T10:26", urlB)
 }
 
 func TestGarminFetcher_Fetch(t *testing.T) {
@@ -46,9 +46,39 @@ func TestGarminFetcher_Fetch(t *testing.T) {
 	defer server.Close()
 
 	fetcher := NewGarminFetcher(server.URL, slog.Default().With("component", "garmin-fetcher"), &emptyMetrics{})
-	res, err := fetcher.Fetch(t.Context(), "garminId")
+	res, err := fetcher.Fetch(t.Context(), model.Pilot{ID: "garminId"}, time.Now())
 	require.NoError(t, err)
 
 	assert.Equal(t, "Tracking turned on from device.", res[0].MsgType)
 	assert.InEpsilon(t, 46.62515, res[1].Latitude, 0.1)
 }
+
+func TestGarminFetcher_FetchWithPassword(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, password, ok := r.BasicAuth()
+		if !ok || password != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+
+		data, err := os.ReadFile("../model/garmin/testdata/feed.kml")
+		assert.NoError(t, err)
+
+		_, _ = w.Write(data)
+	}))
+	defer server.Close()
+
+	var buffer bytes.Buffer
+
+	fetcher := NewGarminFetcher(server.URL, slog.New(slog.NewTextHandler(&buffer, nil)), &emptyMetrics{})
+	res, err := fetcher.Fetch(t.Context(), model.Pilot{ID: "garminId", FeedPassword: "secret"}, time.Now())
+	require.NoError(t, err)
+
+	assert.Len(t, res, 37)
+	assert.NotContains(t, buffer.String(), "secret")
+}
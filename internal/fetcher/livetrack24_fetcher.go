@@ -0,0 +1,109 @@
+package fetcher
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strconv"
+	"time"
+
+	"fahy.xyz/livetrack/internal/model"
+	"fahy.xyz/livetrack/internal/model/livetrack24"
+	"golang.org/x/time/rate"
+)
+
+// Livetrack24Tracker is the tracker type of the livetrack24 fetcher.
+const Livetrack24Tracker = "livetrack24"
+
+// Livetrack24Fetcher retrieves the live tracks streamed to Livetrack24 by a phone app.
+//
+// The ID of the pilot is its Livetrack24 username.
+type Livetrack24Fetcher struct {
+	requester *requester
+	url       string
+	logger    *slog.Logger
+	metrics   metrics
+}
+
+func NewLivetrack24Fetcher(url string, logger *slog.Logger, metrics metrics) *Livetrack24Fetcher {
+	return &Livetrack24Fetcher{
+		requester: newRequester(Livetrack24Tracker, logger, metrics),
+		url:       url,
+		logger:    logger,
+		metrics:   metrics,
+	}
+}
+
+// TrackerType returns the tracker type of the livetrack24 fetcher.
+func (f *Livetrack24Fetcher) TrackerType() string {
+	return Livetrack24Tracker
+}
+
+// Fetch retrieves the points of the pilot's live track since the given time.
+func (f *Livetrack24Fetcher) Fetch(ctx context.Context, pilot model.Pilot, since time.Time) ([]model.Point, error) {
+	url, err := f.createURL(pilot.ID, since)
+	if err != nil {
+		return nil, fmt.Errorf("creating URL: %w", err)
+	}
+
+	f.logger.Info("fetching", "url", url)
+
+	body, err := f.requester.get(ctx, pilot.ID, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("requesting feed: %w", err)
+	}
+
+	points, err := f.ParsePayload(body)
+	if err != nil {
+		return nil, err
+	}
+
+	f.metrics.MessageFetched(Livetrack24Tracker)
+
+	return points, nil
+}
+
+// ParsePayload converts a raw live track into points.
+func (f *Livetrack24Fetcher) ParsePayload(body []byte) ([]model.Point, error) {
+	response, err := livetrack24.Parse(body)
+	if err != nil {
+		return nil, fmt.Errorf("%w: parsing body: %w", ErrMalformedPayload, err)
+	}
+
+	// The feed answers with an error when the user does not exist.
+	if response.Error != "" {
+		return nil, fmt.Errorf("%w: %s", ErrFeedNotFound, response.Error)
+	}
+
+	points, err := response.ToPoints()
+	if err != nil {
+		return nil, fmt.Errorf("%w: parsing points: %w", ErrMalformedPayload, err)
+	}
+
+	return points, nil
+}
+
+// SetArchive stores the raw responses of the feed in the archive.
+func (f *Livetrack24Fetcher) SetArchive(archive Archive) {
+	f.requester.archive = archive
+}
+
+// SetRateLimit sets the minimum delay between two requests to the feed, for all the pilots, 0 to not throttle.
+func (f *Livetrack24Fetcher) SetRateLimit(interval time.Duration) {
+	f.requester.limiter = rate.NewLimiter(rate.Every(interval), 1)
+}
+
+// createURL returns the feed URL with the points since the given time.
+//
+// The path follows the live tracking API of the Livetrack24 documentation wiki, it has not been checked against the
+// feed yet and the app key required by the API is not sent, so the fetcher is only registered when its base URL is
+// configured.
+func (f *Livetrack24Fetcher) createURL(username string, since time.Time) (string, error) {
+	s, err := url.JoinPath(f.url, "username", username, "from", strconv.FormatInt(since.Unix(), 10))
+	if err != nil {
+		return "", fmt.Errorf("joining path: %w", err)
+	}
+
+	return s, nil
+}
@@ -0,0 +1,72 @@
+package fetcher
+
+import (
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"fahy.xyz/livetrack/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLivetrack24Fetcher_createURL(t *testing.T) {
+	t.Parallel()
+
+	fetcher := NewLivetrack24Fetcher(
+		"https://api.livetrack24.com/api/v2/op/getTrackPoints/",
+		slog.Default().With("component", "livetrack24-fetcher"),
+		&emptyMetrics{},
+	)
+	url, err := fetcher.createURL("pilotlt24", time.Date(2023, time.Month(8), 23, 10, 16, 45, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Equal(t, "https://api.livetrack24.com/api/v2/op/getTrackPoints/username/pilotlt24/from/1692785805", url)
+}
+
+func TestLivetrack24Fetcher_Fetch(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/username/pilotlt24/from/1692785805", r.URL.Path)
+		w.WriteHeader(http.StatusOK)
+
+		data, err := os.ReadFile("../model/livetrack24/testdata/response.json")
+		assert.NoError(t, err)
+
+		_, _ = w.Write(data)
+	}))
+	defer server.Close()
+
+	fetcher := NewLivetrack24Fetcher(server.URL, slog.Default().With("component", "livetrack24-fetcher"), &emptyMetrics{})
+	res, err := fetcher.Fetch(
+		t.Context(),
+		model.Pilot{ID: "pilotlt24"},
+		time.Date(2023, time.Month(8), 23, 10, 16, 45, 0, time.UTC),
+	)
+	require.NoError(t, err)
+
+	assert.Len(t, res, 3)
+	assert.InEpsilon(t, 46.62861, res[1].Latitude, 0.1)
+	assert.InDelta(t, 32.4, res[1].Velocity, 0.01)
+}
+
+func TestLivetrack24Fetcher_FetchNotFound(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+
+		data, err := os.ReadFile("../model/livetrack24/testdata/response_not_found.json")
+		assert.NoError(t, err)
+
+		_, _ = w.Write(data)
+	}))
+	defer server.Close()
+
+	fetcher := NewLivetrack24Fetcher(server.URL, slog.Default().With("component", "livetrack24-fetcher"), &emptyMetrics{})
+	_, err := fetcher.Fetch(t.Context(), model.Pilot{ID: "unknown"}, time.Now())
+	require.ErrorIs(t, err, ErrFeedNotFound)
+}
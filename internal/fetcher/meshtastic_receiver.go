@@ -0,0 +1,158 @@
+package fetcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"fahy.xyz/livetrack/internal/model"
+	"fahy.xyz/livetrack/internal/model/meshtastic"
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+)
+
+// MeshtasticTracker is the tracker type of the pilots carrying a Meshtastic node.
+const MeshtasticTracker = "meshtastic"
+
+const (
+	// meshtasticReconnectDelay is the maximum delay before connecting again to the broker.
+	meshtasticReconnectDelay = time.Minute
+	// meshtasticDisconnectQuiesce is the time given to the client to finish its work when stopping, in ms.
+	meshtasticDisconnectQuiesce = 250
+)
+
+// MeshtasticBroker is the MQTT broker receiving the packets relayed by the Meshtastic gateways.
+type MeshtasticBroker struct {
+	URL      string
+	Topic    string
+	ClientID string
+	Username string
+	Password model.Secret
+}
+
+// MeshtasticReceiver subscribes to the JSON packets of the Meshtastic nodes published on an MQTT broker.
+//
+// The ID of the pilot is the ID of its node, e.g. !7efeee00.
+type MeshtasticReceiver struct {
+	broker  MeshtasticBroker
+	store   store
+	logger  *slog.Logger
+	metrics metrics
+
+	mu    sync.Mutex
+	nodes map[string]struct{}
+}
+
+func NewMeshtasticReceiver(
+	broker MeshtasticBroker,
+	store store,
+	logger *slog.Logger,
+	metrics metrics,
+) *MeshtasticReceiver {
+	return &MeshtasticReceiver{
+		broker:  broker,
+		store:   store,
+		logger:  logger,
+		metrics: metrics,
+		nodes:   make(map[string]struct{}),
+	}
+}
+
+// UpdatePilots sets the pilots to receive, only the pilots with the meshtastic tracker type are kept.
+func (r *MeshtasticReceiver) UpdatePilots(pilots []model.Pilot) {
+	nodes := make(map[string]struct{})
+
+	for _, pilot := range pilots {
+		if pilot.TrackerType == MeshtasticTracker {
+			nodes[pilot.ID] = struct{}{}
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.nodes = nodes
+}
+
+// Run receives the packets until the context is done.
+//
+// The client connects again to the broker on its own and subscribes again on each connection.
+func (r *MeshtasticReceiver) Run(ctx context.Context) error {
+	options := mqtt.NewClientOptions().
+		AddBroker(r.broker.URL).
+		SetClientID(r.broker.ClientID).
+		SetUsername(r.broker.Username).
+		SetPassword(string(r.broker.Password)).
+		SetConnectTimeout(HTTPTimeout).
+		SetAutoReconnect(true).
+		SetConnectRetry(true).
+		SetMaxReconnectInterval(meshtasticReconnectDelay).
+		SetOnConnectHandler(func(client mqtt.Client) {
+			r.logger.Info("Connected, subscribing", "broker", r.broker.URL, "topic", r.broker.Topic)
+
+			token := client.Subscribe(r.broker.Topic, 0, func(_ mqtt.Client, message mqtt.Message) {
+				r.handleMessage(ctx, message.Payload())
+			})
+			if token.WaitTimeout(HTTPTimeout) && token.Error() != nil {
+				r.logger.Error("Subscribing", "topic", r.broker.Topic, "error", token.Error())
+			}
+		}).
+		SetConnectionLostHandler(func(_ mqtt.Client, err error) {
+			r.metrics.FetchFailed(MeshtasticTracker, ErrorKind(ErrUpstreamDown))
+			r.logger.Warn("Connection lost", "broker", r.broker.URL, "error", err)
+		})
+
+	client := mqtt.NewClient(options)
+
+	// With the connect retry, the token is only done once connected.
+	client.Connect()
+
+	<-ctx.Done()
+
+	client.Disconnect(meshtasticDisconnectQuiesce)
+	r.logger.Info("Disconnected", "broker", r.broker.URL)
+
+	return nil
+}
+
+func (r *MeshtasticReceiver) handleMessage(ctx context.Context, payload []byte) {
+	packet, err := meshtastic.Parse(payload)
+	if err != nil {
+		r.logger.Warn("Parsing packet", "error", err)
+
+		return
+	}
+
+	if !r.isPilotNode(packet.NodeID()) {
+		return
+	}
+
+	point, err := packet.ToPoint()
+	if err != nil {
+		// The other packets and the positions without fix are expected.
+		if !errors.Is(err, meshtastic.ErrNotPosition) && !errors.Is(err, meshtastic.ErrNoPosition) {
+			r.logger.Warn("Parsing position", "node", packet.NodeID(), "error", err)
+		}
+
+		return
+	}
+
+	if err = r.store.WriteTrack(ctx, packet.NodeID(), []model.Point{point}); err != nil {
+		r.logger.Error("Writing track", "ID", packet.NodeID(), "error", fmt.Errorf("writing packet %d: %w", packet.ID, err))
+
+		return
+	}
+
+	r.metrics.MessageFetched(MeshtasticTracker)
+}
+
+func (r *MeshtasticReceiver) isPilotNode(nodeID string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	_, ok := r.nodes[nodeID]
+
+	return ok
+}
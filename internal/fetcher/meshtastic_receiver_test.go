@@ -0,0 +1,191 @@
+package fetcher
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"io"
+	"log/slog"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"fahy.xyz/livetrack/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// MQTT control packet types used by the broker.
+const (
+	mqttConnect     = 0x10
+	mqttConnack     = 0x20
+	mqttPublish     = 0x30
+	mqttSubscribe   = 0x80
+	mqttSuback      = 0x90
+	mqttPingreq     = 0xC0
+	mqttPingresp    = 0xD0
+	mqttDisconnect  = 0xE0
+	mqttPacketTypes = 0xF0
+)
+
+// mqttBroker is a minimal in-process MQTT 3.1.1 broker, publishing the messages to each client after its subscription.
+type mqttBroker struct {
+	listener      net.Listener
+	messages      [][]byte
+	subscriptions chan string
+}
+
+func newMQTTBroker(t *testing.T, messages ...[]byte) *mqttBroker {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	broker := &mqttBroker{listener: listener, messages: messages, subscriptions: make(chan string, 10)}
+
+	go broker.serve()
+
+	t.Cleanup(func() { _ = listener.Close() })
+
+	return broker
+}
+
+func (b *mqttBroker) url() string {
+	return "tcp://" + b.listener.Addr().String()
+}
+
+func (b *mqttBroker) serve() {
+	for {
+		conn, err := b.listener.Accept()
+		if err != nil {
+			return
+		}
+
+		go b.handle(conn)
+	}
+}
+
+func (b *mqttBroker) handle(conn net.Conn) {
+	defer conn.Close()
+
+	reader := bufio.NewReader(conn)
+
+	for {
+		header, body, err := readMQTTPacket(reader)
+		if err != nil {
+			return
+		}
+
+		switch header & mqttPacketTypes {
+		case mqttConnect:
+			_, _ = conn.Write([]byte{mqttConnack, 0x02, 0x00, 0x00})
+		case mqttSubscribe:
+			// The body is the packet ID, then the topic filters with their QoS.
+			topicLength := binary.BigEndian.Uint16(body[2:4])
+			b.subscriptions <- string(body[4 : 4+topicLength])
+
+			_, _ = conn.Write([]byte{mqttSuback, 0x03, body[0], body[1], 0x00})
+
+			for _, message := range b.messages {
+				_, _ = conn.Write(mqttPublishPacket("msh/EU_868/2/json/LongFast/!a1b2c3d4", message))
+			}
+		case mqttPingreq:
+			_, _ = conn.Write([]byte{mqttPingresp, 0x00})
+		case mqttDisconnect:
+			return
+		}
+	}
+}
+
+func readMQTTPacket(reader *bufio.Reader) (byte, []byte, error) {
+	header, err := reader.ReadByte()
+	if err != nil {
+		return 0, nil, err
+	}
+
+	length, err := binary.ReadUvarint(reader)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	body := make([]byte, length)
+	if _, err = io.ReadFull(reader, body); err != nil {
+		return 0, nil, err
+	}
+
+	return header, body, nil
+}
+
+func mqttPublishPacket(topic string, payload []byte) []byte {
+	body := binary.BigEndian.AppendUint16(nil, uint16(len(topic))) //nolint:gosec // The topic is short.
+	body = append(body, topic...)
+	body = append(body, payload...)
+
+	packet := binary.AppendUvarint([]byte{mqttPublish}, uint64(len(body)))
+
+	return append(packet, body...)
+}
+
+func TestMeshtasticReceiver_Run(t *testing.T) {
+	t.Parallel()
+
+	position, err := os.ReadFile("../model/meshtastic/testdata/position.json")
+	require.NoError(t, err)
+	telemetry, err := os.ReadFile("../model/meshtastic/testdata/telemetry.json")
+	require.NoError(t, err)
+
+	broker := newMQTTBroker(t,
+		telemetry,
+		// Not a pilot's node.
+		[]byte(`{"from":1,"type":"position","payload":{"latitude_i":466251500,"longitude_i":72061300}}`),
+		[]byte("not json"),
+		position,
+	)
+
+	store := &fakeStore{tracks: make(map[string][]model.Point)}
+	receiver := NewMeshtasticReceiver(
+		MeshtasticBroker{URL: broker.url(), Topic: "msh/+/2/json/#", ClientID: "livetrack-test"},
+		store,
+		slog.Default(),
+		&emptyMetrics{},
+	)
+	receiver.UpdatePilots([]model.Pilot{
+		{ID: "!7efeee00", TrackerType: MeshtasticTracker},
+		{ID: "spotId", TrackerType: SpotTracker},
+	})
+
+	ctx, cancel := context.WithCancel(t.Context())
+	done := make(chan error)
+
+	go func() {
+		done <- receiver.Run(ctx)
+	}()
+
+	select {
+	case topic := <-broker.subscriptions:
+		assert.Equal(t, "msh/+/2/json/#", topic)
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "no subscription received")
+	}
+
+	assert.Eventually(t, func() bool {
+		store.mu.Lock()
+		defer store.mu.Unlock()
+
+		return len(store.tracks["!7efeee00"]) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	cancel()
+	require.NoError(t, <-done)
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	point := store.tracks["!7efeee00"][0]
+	assert.Equal(t, time.Unix(1692785805, 0).UTC(), point.DateTime)
+	assert.InDelta(t, 46.62515, point.Latitude, 0.000001)
+	assert.InDelta(t, 7.20613, point.Longitude, 0.000001)
+	assert.Equal(t, 1603, point.Altitude)
+	assert.Len(t, store.tracks, 1)
+}
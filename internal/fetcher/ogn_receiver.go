@@ -0,0 +1,282 @@
+package fetcher
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"fahy.xyz/livetrack/internal/model"
+	"fahy.xyz/livetrack/internal/model/ogn"
+)
+
+// OGNTracker is the tracker type of the pilots received from the Open Glider Network.
+const OGNTracker = "ogn"
+
+const (
+	// ognReadTimeout is the maximum delay without data, the server sends a keepalive every 20s.
+	ognReadTimeout = time.Minute
+	// ognKeepalive is the interval between two keepalives sent to the server.
+	ognKeepalive = 4 * time.Minute
+	// ognReconnectDelay is the delay before connecting again after an error.
+	ognReconnectDelay = 10 * time.Second
+)
+
+// OGNReceiver receives the positions of the FLARM and FANET devices from an APRS-IS server.
+//
+// Unlike the fetchers, the positions are pushed by the server on a long-lived connection.
+// The ID of the pilot is the callsign of its device, e.g. FLRDDE626.
+type OGNReceiver struct {
+	address  string
+	callsign string
+	// minInterval is the minimum delay between two points stored for a device.
+	minInterval time.Duration
+	store       store
+	logger      *slog.Logger
+	metrics     metrics
+
+	mu         sync.Mutex
+	devices    []string
+	lastPoints map[string]time.Time
+	updated    chan struct{}
+}
+
+func NewOGNReceiver(
+	address, callsign string,
+	minInterval time.Duration,
+	store store,
+	logger *slog.Logger,
+	metrics metrics,
+) *OGNReceiver {
+	return &OGNReceiver{
+		address:     address,
+		callsign:    callsign,
+		minInterval: minInterval,
+		store:       store,
+		logger:      logger,
+		metrics:     metrics,
+		lastPoints:  make(map[string]time.Time),
+		updated:     make(chan struct{}, 1),
+	}
+}
+
+// UpdatePilots sets the pilots to receive, only the pilots with the ogn tracker type are kept.
+//
+// If the devices changed, the receiver connects again with the new filter.
+func (r *OGNReceiver) UpdatePilots(pilots []model.Pilot) {
+	devices := []string{}
+
+	for _, pilot := range pilots {
+		if pilot.TrackerType == OGNTracker {
+			devices = append(devices, pilot.ID)
+		}
+	}
+
+	slices.Sort(devices)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if slices.Equal(devices, r.devices) {
+		return
+	}
+
+	r.devices = devices
+
+	select {
+	case r.updated <- struct{}{}:
+	default:
+	}
+}
+
+// Run receives the positions until the context is done, connecting again on errors.
+func (r *OGNReceiver) Run(ctx context.Context) error {
+	for {
+		err := r.receive(ctx)
+		if ctx.Err() != nil {
+			return nil //nolint:nilerr // The context is done, the receiver is stopped.
+		}
+
+		if err == nil {
+			continue
+		}
+
+		r.metrics.FetchFailed(OGNTracker, ErrorKind(err))
+		r.logger.Warn("Receiving positions", "address", r.address, "retry", ognReconnectDelay, "error", err)
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(ognReconnectDelay):
+		}
+	}
+}
+
+// receive connects to the server and handles the positions until an error or an update of the pilots.
+func (r *OGNReceiver) receive(ctx context.Context) error {
+	// The devices are read after, a pending update is already taken into account.
+	select {
+	case <-r.updated:
+	default:
+	}
+
+	devices := r.getDevices()
+	if len(devices) == 0 {
+		r.logger.Debug("No device to receive")
+
+		select {
+		case <-ctx.Done():
+		case <-r.updated:
+		}
+
+		return nil
+	}
+
+	dialer := net.Dialer{Timeout: HTTPTimeout}
+
+	conn, err := dialer.DialContext(ctx, "tcp", r.address)
+	if err != nil {
+		return fmt.Errorf("%w: connecting: %w", ErrUpstreamDown, err)
+	}
+
+	defer conn.Close()
+
+	r.logger.Info("Connected", "address", r.address, "devices", devices)
+
+	// The filter only lets the positions of the devices through.
+	login := fmt.Sprintf(
+		"user %s pass -1 vers livetrack 1.0 filter b/%s\r\n",
+		r.callsign, strings.Join(devices, "/"),
+	)
+	if _, err = conn.Write([]byte(login)); err != nil {
+		return fmt.Errorf("%w: logging in: %w", ErrUpstreamDown, err)
+	}
+
+	connCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	go r.keepalive(connCtx, cancel, conn)
+
+	if err = r.readPositions(ctx, conn); err != nil && connCtx.Err() == nil {
+		return err
+	}
+
+	// The connection was closed on purpose, to stop or to update the filter.
+	return nil
+}
+
+// keepalive writes a comment periodically and closes the connection when the context is
+// done or the pilots are updated, to unblock the read.
+func (r *OGNReceiver) keepalive(ctx context.Context, cancel context.CancelFunc, conn net.Conn) {
+	ticker := time.NewTicker(ognKeepalive)
+	defer ticker.Stop()
+
+	defer conn.Close()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-r.updated:
+			r.logger.Info("Pilots updated, connecting again")
+			cancel()
+
+			return
+		case <-ticker.C:
+			if _, err := conn.Write([]byte("# keepalive livetrack\r\n")); err != nil {
+				r.logger.Warn("Sending keepalive", "error", err)
+			}
+		}
+	}
+}
+
+// readPositions reads the lines of the connection and stores the positions of the devices.
+func (r *OGNReceiver) readPositions(ctx context.Context, conn net.Conn) error {
+	scanner := bufio.NewScanner(conn)
+
+	for {
+		if err := conn.SetReadDeadline(time.Now().Add(ognReadTimeout)); err != nil {
+			return fmt.Errorf("setting read deadline: %w", err)
+		}
+
+		if !scanner.Scan() {
+			break
+		}
+
+		line := scanner.Text()
+		// The comments are the server messages and keepalives.
+		if strings.HasPrefix(line, "#") {
+			r.logger.Debug("Server message", "line", line)
+
+			continue
+		}
+
+		r.handleLine(ctx, line)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("%w: reading: %w", ErrUpstreamDown, err)
+	}
+
+	return fmt.Errorf("%w: connection closed by the server", ErrUpstreamDown)
+}
+
+func (r *OGNReceiver) handleLine(ctx context.Context, line string) {
+	position, err := ogn.Parse(line, time.Now())
+	if err != nil {
+		if !errors.Is(err, ogn.ErrNotPosition) {
+			r.logger.Warn("Parsing position", "line", line, "error", err)
+		}
+
+		return
+	}
+
+	if !position.AllowsTracking() {
+		r.logger.Debug("Position of a device not to be tracked", "ID", position.Callsign)
+
+		return
+	}
+
+	if !r.shouldStore(position) {
+		return
+	}
+
+	if err = r.store.WriteTrack(ctx, position.Callsign, []model.Point{position.ToPoint()}); err != nil {
+		r.logger.Error("Writing track", "ID", position.Callsign, "error", err)
+
+		return
+	}
+
+	r.metrics.MessageFetched(OGNTracker)
+}
+
+// shouldStore returns true if the position is from a pilot's device and not too close to the previous one.
+func (r *OGNReceiver) shouldStore(position ogn.Position) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, found := slices.BinarySearch(r.devices, position.Callsign); !found {
+		return false
+	}
+
+	if position.DateTime.Sub(r.lastPoints[position.Callsign]) < r.minInterval {
+		return false
+	}
+
+	r.lastPoints[position.Callsign] = position.DateTime
+
+	return true
+}
+
+func (r *OGNReceiver) getDevices() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return slices.Clone(r.devices)
+}
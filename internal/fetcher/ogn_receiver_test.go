@@ -0,0 +1,168 @@
+package fetcher
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"testing"
+	"time"
+
+	"fahy.xyz/livetrack/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// aprsServer is a local stand-in for an APRS-IS server, sending the lines to each client after the login.
+type aprsServer struct {
+	listener net.Listener
+	lines    []string
+	logins   chan string
+}
+
+func newAPRSServer(t *testing.T, lines ...string) *aprsServer {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	server := &aprsServer{listener: listener, lines: lines, logins: make(chan string, 10)}
+
+	go server.serve()
+
+	t.Cleanup(func() { _ = listener.Close() })
+
+	return server
+}
+
+func (s *aprsServer) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+
+		go func() {
+			defer conn.Close()
+
+			_, _ = fmt.Fprint(conn, "# aprsc 2.1.14-g408ed49\r\n")
+
+			login, err := bufio.NewReader(conn).ReadString('\n')
+			if err != nil {
+				return
+			}
+
+			s.logins <- login
+
+			_, _ = fmt.Fprint(conn, "# logresp LIVETRACK unverified, server GLIDERN1\r\n")
+
+			for _, line := range s.lines {
+				_, _ = fmt.Fprint(conn, line+"\r\n")
+			}
+
+			// Keep the connection open like the real server.
+			time.Sleep(time.Minute)
+		}()
+	}
+}
+
+func TestOGNReceiver_Run(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now().UTC()
+	timestamp := now.Format("150405")
+	server := newAPRSServer(t,
+		"FLRDDE626>APRS,qAS,EGHL:/"+timestamp+"h4637.51N/00712.37E'045/018/A=005262 !W80! id0ADDE626 +198fpm",
+		// Too close to the previous position of the device.
+		"FLRDDE626>APRS,qAS,EGHL:/"+timestamp+"h4637.60N/00712.40E'045/018/A=005300 id0ADDE626 +198fpm",
+		// A pilot's device with the no-tracking flag.
+		"FLRDD1234>APRS,qAS,EGHL:/"+timestamp+"h4637.51N/00712.37E'045/018/A=005262 id4ADD1234 +198fpm",
+		// Not a pilot's device.
+		"FLR123456>APRS,qAS,EGHL:/"+timestamp+"h4637.51N/00712.37E'045/018/A=005262 id0A123456 +198fpm",
+		"EGHL>OGNSDR,TCPIP*,qAC,GLIDERN1:>"+timestamp+"h v0.2.8 CPU:0.3",
+		"FNT11F3A4>OGNFNT,qAS,Hochfeld:/"+timestamp+"h4638.00N/00713.00E'090/010/A=004000 id1E11F3A4",
+	)
+
+	store := &fakeStore{tracks: make(map[string][]model.Point)}
+	receiver := NewOGNReceiver(server.listener.Addr().String(), "LIVETRACK", time.Minute, store, slog.Default(), &emptyMetrics{})
+	receiver.UpdatePilots([]model.Pilot{
+		{ID: "FNT11F3A4", TrackerType: OGNTracker},
+		{ID: "FLRDDE626", TrackerType: OGNTracker},
+		{ID: "FLRDD1234", TrackerType: OGNTracker},
+		{ID: "spotId", TrackerType: SpotTracker},
+	})
+
+	ctx, cancel := context.WithCancel(t.Context())
+	done := make(chan error)
+
+	go func() {
+		done <- receiver.Run(ctx)
+	}()
+
+	select {
+	case login := <-server.logins:
+		assert.Equal(t, "user LIVETRACK pass -1 vers livetrack 1.0 filter b/FLRDD1234/FLRDDE626/FNT11F3A4\r\n", login)
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "no login received")
+	}
+
+	assert.Eventually(t, func() bool {
+		store.mu.Lock()
+		defer store.mu.Unlock()
+
+		return len(store.tracks["FNT11F3A4"]) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	cancel()
+	require.NoError(t, <-done)
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	require.Len(t, store.tracks["FLRDDE626"], 1)
+	assert.InDelta(t, 46.6253, store.tracks["FLRDDE626"][0].Latitude, 0.000001)
+	assert.Equal(t, model.MsgTypeTrack, store.tracks["FLRDDE626"][0].MsgType)
+	assert.NotContains(t, store.tracks, "FLRDD1234")
+	assert.NotContains(t, store.tracks, "FLR123456")
+	assert.NotContains(t, store.tracks, "EGHL")
+}
+
+func TestOGNReceiver_UpdatePilots(t *testing.T) {
+	t.Parallel()
+
+	server := newAPRSServer(t)
+
+	store := &fakeStore{tracks: make(map[string][]model.Point)}
+	receiver := NewOGNReceiver(server.listener.Addr().String(), "LIVETRACK", time.Minute, store, slog.Default(), &emptyMetrics{})
+
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+
+	go func() {
+		_ = receiver.Run(ctx)
+	}()
+
+	// Without device, the receiver waits for the pilots before connecting.
+	receiver.UpdatePilots([]model.Pilot{{ID: "FLRDDE626", TrackerType: OGNTracker}})
+
+	select {
+	case login := <-server.logins:
+		assert.Contains(t, login, "filter b/FLRDDE626\r\n")
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "no login received")
+	}
+
+	// A new device connects again with the new filter.
+	receiver.UpdatePilots([]model.Pilot{
+		{ID: "FLRDDE626", TrackerType: OGNTracker},
+		{ID: "FNT11F3A4", TrackerType: OGNTracker},
+	})
+
+	select {
+	case login := <-server.logins:
+		assert.Contains(t, login, "filter b/FLRDDE626/FNT11F3A4\r\n")
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "no login received after update")
+	}
+}
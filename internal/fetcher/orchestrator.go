@@ -0,0 +1,147 @@
+package fetcher
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"fahy.xyz/livetrack/internal/model"
+	"github.com/sourcegraph/conc/pool"
+)
+
+// invalidFeedPause is the delay before fetching again a feed not found or private.
+const invalidFeedPause = time.Hour
+
+type store interface {
+	GetLastPointTime(ctx context.Context, pilotID string) (time.Time, error)
+	WriteTrack(ctx context.Context, pilotID string, track []model.Point) error
+}
+
+// Orchestrator fetches the trackers of the pilots with a bounded pool of workers.
+//
+// The requests are throttled by the fetchers of each source, so a worker does not wait for a slot of another source.
+// The pilots whose feed is not found or private are paused for a while.
+type Orchestrator struct {
+	registry *Registry
+	store    store
+	workers  int
+	lookback time.Duration
+	logger   *slog.Logger
+	metrics  metrics
+
+	mu          sync.Mutex
+	pausedUntil map[string]time.Time
+}
+
+// NewOrchestrator creates an orchestrator running at most workers fetches at the same time.
+func NewOrchestrator(
+	registry *Registry,
+	store store,
+	workers int,
+	lookback time.Duration,
+	logger *slog.Logger,
+	metrics metrics,
+) *Orchestrator {
+	return &Orchestrator{
+		registry:    registry,
+		store:       store,
+		workers:     max(workers, 1),
+		lookback:    lookback,
+		logger:      logger,
+		metrics:     metrics,
+		pausedUntil: make(map[string]time.Time),
+	}
+}
+
+// Run fetches and stores the points of all the pilots.
+//
+// Errors are logged and do not stop the fetch of the other pilots.
+func (o *Orchestrator) Run(ctx context.Context, pilots []model.Pilot) {
+	workerPool := pool.New().WithMaxGoroutines(o.workers)
+
+	for _, pilot := range pilots {
+		workerPool.Go(func() {
+			o.fetchPilot(ctx, pilot)
+		})
+	}
+
+	workerPool.Wait()
+}
+
+func (o *Orchestrator) fetchPilot(ctx context.Context, pilot model.Pilot) {
+	if o.isPaused(pilot.ID) {
+		o.logger.Debug("Pilot paused", "ID", pilot.ID)
+
+		return
+	}
+
+	trackerFetcher, err := o.registry.Get(pilot.TrackerType)
+	if err != nil {
+		o.logger.Error("Unknown tracker", "pilot", pilot, "error", err)
+
+		return
+	}
+
+	lastPoint, err := o.store.GetLastPointTime(ctx, pilot.ID)
+	if err != nil {
+		o.logger.Error("Retrieving last point", "ID", pilot.ID, "error", err)
+
+		return
+	}
+
+	since := StartTime(lastPoint, time.Now(), o.lookback)
+
+	points, err := trackerFetcher.Fetch(ctx, pilot, since)
+	if err != nil {
+		o.handleError(pilot, err)
+
+		return
+	}
+
+	o.logger.Debug("Fetched", "points", points)
+
+	if len(points) > 0 {
+		if err = o.store.WriteTrack(ctx, pilot.ID, points); err != nil {
+			o.logger.Error("Writing track", "ID", pilot.ID, "track", points, "error", err)
+		}
+	}
+}
+
+// handleError reacts to a fetch error depending on its kind.
+func (o *Orchestrator) handleError(pilot model.Pilot, err error) {
+	o.metrics.FetchFailed(pilot.TrackerType, ErrorKind(err))
+
+	switch {
+	case errors.Is(err, ErrFeedNotFound), errors.Is(err, ErrFeedPrivate):
+		// The configuration of the pilot is wrong, no need to try at each fetch.
+		o.logger.Warn("Invalid feed, pausing pilot", "ID", pilot.ID, "pause", invalidFeedPause, "error", err)
+		o.pause(pilot.ID, invalidFeedPause)
+	case errors.Is(err, ErrRateLimited), errors.Is(err, ErrCircuitOpen), errors.Is(err, ErrUpstreamDown):
+		o.logger.Warn("Source unavailable", "type", pilot.TrackerType, "ID", pilot.ID, "error", err)
+	default:
+		o.logger.Error("Retrieving tracker", "type", pilot.TrackerType, "ID", pilot.ID, "error", err)
+	}
+}
+
+func (o *Orchestrator) pause(pilotID string, duration time.Duration) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.pausedUntil[pilotID] = time.Now().Add(duration)
+}
+
+func (o *Orchestrator) isPaused(pilotID string) bool {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	until, ok := o.pausedUntil[pilotID]
+	if ok && time.Now().After(until) {
+		delete(o.pausedUntil, pilotID)
+
+		return false
+	}
+
+	return ok
+}
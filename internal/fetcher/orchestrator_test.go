@@ -0,0 +1,150 @@
+package fetcher
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"fahy.xyz/livetrack/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+// concurrency counts the fetches running at the same time.
+type concurrency struct {
+	mu         sync.Mutex
+	running    int
+	maxRunning int
+}
+
+type fakeFetcher struct {
+	trackerType string
+	delay       time.Duration
+	err         error
+	concurrency *concurrency
+
+	mu    sync.Mutex
+	calls []time.Time
+}
+
+func (f *fakeFetcher) TrackerType() string {
+	return f.trackerType
+}
+
+func (f *fakeFetcher) Fetch(_ context.Context, pilot model.Pilot, _ time.Time) ([]model.Point, error) {
+	f.mu.Lock()
+	f.calls = append(f.calls, time.Now())
+	f.mu.Unlock()
+
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	f.concurrency.mu.Lock()
+	f.concurrency.running++
+	f.concurrency.maxRunning = max(f.concurrency.maxRunning, f.concurrency.running)
+	f.concurrency.mu.Unlock()
+
+	time.Sleep(f.delay)
+
+	f.concurrency.mu.Lock()
+	f.concurrency.running--
+	f.concurrency.mu.Unlock()
+
+	return []model.Point{{DateTime: time.Now(), MsgType: pilot.ID}}, nil
+}
+
+type fakeStore struct {
+	mu     sync.Mutex
+	tracks map[string][]model.Point
+}
+
+func (s *fakeStore) GetLastPointTime(context.Context, string) (time.Time, error) {
+	return time.Time{}, nil
+}
+
+func (s *fakeStore) WriteTrack(_ context.Context, pilotID string, track []model.Point) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.tracks[pilotID] = append(s.tracks[pilotID], track...)
+
+	return nil
+}
+
+func TestOrchestrator_Run(t *testing.T) {
+	t.Parallel()
+
+	counter := &concurrency{}
+	spotFetcher := &fakeFetcher{trackerType: SpotTracker, delay: 20 * time.Millisecond, concurrency: counter}
+	garminFetcher := &fakeFetcher{trackerType: GarminTracker, delay: 20 * time.Millisecond, concurrency: counter}
+	store := &fakeStore{tracks: make(map[string][]model.Point)}
+
+	pilots := []model.Pilot{
+		{ID: "spot-1", TrackerType: SpotTracker},
+		{ID: "spot-2", TrackerType: SpotTracker},
+		{ID: "spot-3", TrackerType: SpotTracker},
+		{ID: "garmin-1", TrackerType: GarminTracker},
+		{ID: "garmin-2", TrackerType: GarminTracker},
+		{ID: "garmin-3", TrackerType: GarminTracker},
+		{ID: "unknown-1", TrackerType: "unknown"},
+	}
+
+	orchestrator := NewOrchestrator(
+		NewRegistry(spotFetcher, garminFetcher),
+		store,
+		2,
+		time.Hour,
+		slog.Default(),
+		&emptyMetrics{},
+	)
+	orchestrator.Run(t.Context(), pilots)
+
+	// All the pilots with a known tracker are written.
+	assert.Len(t, store.tracks, 6)
+	assert.NotContains(t, store.tracks, "unknown-1")
+
+	// The pool is bounded.
+	assert.LessOrEqual(t, counter.maxRunning, 2)
+	assert.Len(t, spotFetcher.calls, 3)
+}
+
+type failedMetrics struct {
+	emptyMetrics
+
+	mu    sync.Mutex
+	kinds []string
+}
+
+func (m *failedMetrics) FetchFailed(_, kind string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.kinds = append(m.kinds, kind)
+}
+
+func TestOrchestrator_RunErrors(t *testing.T) {
+	t.Parallel()
+
+	spotFetcher := &fakeFetcher{trackerType: SpotTracker, err: ErrFeedNotFound, concurrency: &concurrency{}}
+	garminFetcher := &fakeFetcher{trackerType: GarminTracker, err: ErrUpstreamDown, concurrency: &concurrency{}}
+	store := &fakeStore{tracks: make(map[string][]model.Point)}
+	metrics := &failedMetrics{}
+
+	pilots := []model.Pilot{
+		{ID: "spot-1", TrackerType: SpotTracker},
+		{ID: "garmin-1", TrackerType: GarminTracker},
+	}
+
+	orchestrator := NewOrchestrator(NewRegistry(spotFetcher, garminFetcher), store, 1, time.Hour, slog.Default(), metrics)
+	orchestrator.Run(t.Context(), pilots)
+	orchestrator.Run(t.Context(), pilots)
+
+	assert.Empty(t, store.tracks)
+	assert.Equal(t, []string{"not_found", "upstream_down", "upstream_down"}, metrics.kinds)
+
+	// The pilot with a feed not found is paused, the one with the source down is not.
+	assert.Len(t, spotFetcher.calls, 1)
+	assert.Len(t, garminFetcher.calls, 2)
+}
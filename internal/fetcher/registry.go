@@ -0,0 +1,68 @@
+package fetcher
+
+import (
+	"errors"
+	"fmt"
+
+	"fahy.xyz/livetrack/internal/model"
+)
+
+var (
+	ErrUnknownTracker = errors.New("unknown tracker type")
+	ErrNoParser       = errors.New("no payload parser for tracker type")
+)
+
+// Registry holds the fetchers by tracker type.
+type Registry struct {
+	fetchers map[string]Fetcher
+}
+
+// NewRegistry creates a registry with the given fetchers registered.
+func NewRegistry(fetchers ...Fetcher) *Registry {
+	registry := &Registry{
+		fetchers: make(map[string]Fetcher),
+	}
+
+	for _, fetcher := range fetchers {
+		registry.Register(fetcher)
+	}
+
+	return registry
+}
+
+// Register adds the fetcher under its tracker type.
+//
+// A fetcher already registered for the same tracker type is replaced.
+func (r *Registry) Register(fetcher Fetcher) {
+	r.fetchers[fetcher.TrackerType()] = fetcher
+}
+
+// Get returns the fetcher of the given tracker type.
+func (r *Registry) Get(trackerType string) (Fetcher, error) {
+	fetcher, ok := r.fetchers[trackerType]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownTracker, trackerType)
+	}
+
+	return fetcher, nil
+}
+
+// ParsePayload parses an archived payload with the fetcher of its source.
+func (r *Registry) ParsePayload(payload model.Payload) ([]model.Point, error) {
+	fetcher, err := r.Get(payload.Source)
+	if err != nil {
+		return nil, err
+	}
+
+	parser, ok := fetcher.(Parser)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNoParser, payload.Source)
+	}
+
+	points, err := parser.ParsePayload(payload.Content)
+	if err != nil {
+		return nil, fmt.Errorf("parsing payload %d: %w", payload.ID, err)
+	}
+
+	return points, nil
+}
@@ -0,0 +1,60 @@
+package fetcher
+
+import (
+	"log/slog"
+	"os"
+	"testing"
+
+	"fahy.xyz/livetrack/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry_Get(t *testing.T) {
+	t.Parallel()
+
+	garminFetcher := NewGarminFetcher("https://share.garmin.com/Feed/Share/", slog.Default(), &emptyMetrics{})
+	spotFetcher := NewSpotFetcher("https://api.findmespot.com/", slog.Default(), &emptyMetrics{})
+
+	registry := NewRegistry(garminFetcher, spotFetcher)
+
+	fetcherA, err := registry.Get(GarminTracker)
+	require.NoError(t, err)
+	assert.Equal(t, garminFetcher, fetcherA)
+
+	fetcherB, err := registry.Get(SpotTracker)
+	require.NoError(t, err)
+	assert.Equal(t, spotFetcher, fetcherB)
+
+	_, err = registry.Get("unknown")
+	require.ErrorIs(t, err, ErrUnknownTracker)
+}
+
+func TestRegistry_ParsePayload(t *testing.T) {
+	t.Parallel()
+
+	registry := NewRegistry(
+		NewGarminFetcher("https://share.garmin.com/Feed/Share/", slog.Default(), &emptyMetrics{}),
+		NewSpotFetcher("https://api.findmespot.com/", slog.Default(), &emptyMetrics{}),
+	)
+
+	content, err := os.ReadFile("../model/spot/testdata/response_full.json")
+	require.NoError(t, err)
+
+	points, err := registry.ParsePayload(model.Payload{Source: SpotTracker, Content: content})
+	require.NoError(t, err)
+	assert.Len(t, points, 4)
+
+	content, err = os.ReadFile("../model/garmin/testdata/feed.kml")
+	require.NoError(t, err)
+
+	points, err = registry.ParsePayload(model.Payload{Source: GarminTracker, Content: content})
+	require.NoError(t, err)
+	assert.Len(t, points, 37)
+
+	_, err = registry.ParsePayload(model.Payload{Source: SpotTracker, Content: content})
+	require.ErrorIs(t, err, ErrMalformedPayload)
+
+	_, err = registry.ParsePayload(model.Payload{Source: "unknown", Content: content})
+	require.ErrorIs(t, err, ErrUnknownTracker)
+}
@@ -0,0 +1,220 @@
+package fetcher
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+
+	"fahy.xyz/livetrack/internal/model"
+	"golang.org/x/time/rate"
+)
+
+const (
+	defaultMaxRetries       = 3
+	defaultBaseDelay        = time.Second
+	defaultMaxDelay         = 30 * time.Second
+	defaultBreakerThreshold = 5
+	defaultBreakerCooldown  = 5 * time.Minute
+)
+
+// backoff defines the retries of the requests.
+type backoff struct {
+	maxRetries int
+	baseDelay  time.Duration
+	maxDelay   time.Duration
+}
+
+// delay returns a jittered exponential delay for the given attempt.
+func (b backoff) delay(attempt int) time.Duration {
+	ceiling := min(b.baseDelay<<attempt, b.maxDelay)
+	if ceiling <= 0 {
+		return 0
+	}
+
+	return rand.N(ceiling) //nolint:gosec // The jitter does not need a secure random.
+}
+
+// requester executes the requests of a source.
+//
+// Transient errors (network errors, 5xx and 429) are retried with a jittered exponential
+// backoff, honoring the Retry-After header. The failures are recorded in a circuit
+// breaker pausing all the requests to the source when it keeps failing.
+// Each request, retries and pages included, waits for the rate limiter of the source if set.
+type requester struct {
+	client  *http.Client
+	source  string
+	backoff backoff
+	breaker *circuitBreaker
+	limiter *rate.Limiter
+	archive Archive
+	logger  *slog.Logger
+	metrics metrics
+}
+
+func newRequester(source string, logger *slog.Logger, metrics metrics) *requester {
+	return &requester{
+		client: &http.Client{Timeout: HTTPTimeout},
+		source: source,
+		backoff: backoff{
+			maxRetries: defaultMaxRetries,
+			baseDelay:  defaultBaseDelay,
+			maxDelay:   defaultMaxDelay,
+		},
+		breaker: newCircuitBreaker(defaultBreakerThreshold, defaultBreakerCooldown, func(state CircuitState) {
+			logger.Warn("Circuit breaker state changed", "source", source, "state", state)
+			metrics.CircuitStateChanged(source, int(state))
+		}),
+		logger:  logger,
+		metrics: metrics,
+	}
+}
+
+// get executes a GET request on the URL for the pilot and returns the body of the response.
+//
+// The prepare function is called on each request before sending it.
+func (r *requester) get(
+	ctx context.Context,
+	pilotID, url string,
+	prepare func(req *http.Request),
+) ([]byte, error) {
+	for attempt := 0; ; attempt++ {
+		if err := r.breaker.Allow(); err != nil {
+			return nil, fmt.Errorf("requesting %s: %w", r.source, err)
+		}
+
+		if r.limiter != nil {
+			if err := r.limiter.Wait(ctx); err != nil {
+				r.breaker.Abort()
+
+				return nil, fmt.Errorf("waiting for rate limit: %w", err)
+			}
+		}
+
+		body, retryAfter, err := r.do(ctx, pilotID, url, prepare)
+		if err == nil {
+			r.breaker.Success()
+
+			return body, nil
+		}
+
+		if ctx.Err() != nil {
+			r.breaker.Abort()
+
+			return nil, err
+		}
+
+		// The source answered, only the request is at fault.
+		if !isTransient(err) {
+			r.breaker.Success()
+
+			return nil, err
+		}
+
+		r.breaker.Failure()
+
+		// The source asks to wait longer than we are ready to, pause it.
+		if retryAfter > r.backoff.maxDelay {
+			r.breaker.Trip(retryAfter)
+
+			return nil, err
+		}
+
+		if attempt >= r.backoff.maxRetries {
+			return nil, fmt.Errorf("giving up after %d retries: %w", attempt, err)
+		}
+
+		delay := max(retryAfter, r.backoff.delay(attempt))
+		r.logger.Warn("Retrying request", "source", r.source, "attempt", attempt+1, "delay", delay, "error", err)
+		r.metrics.RequestRetried(r.source)
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("waiting before retry: %w", ctx.Err())
+		case <-time.After(delay):
+		}
+	}
+}
+
+// do executes a single request.
+//
+// The delay asked by the source before retrying is returned if any.
+func (r *requester) do(
+	ctx context.Context,
+	pilotID, url string,
+	prepare func(req *http.Request),
+) ([]byte, time.Duration, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, 0, fmt.Errorf("creating request: %w", err)
+	}
+
+	req.Header.Set("User-Agent", "Wget/1.13.4 (linux-gnu)")
+
+	if prepare != nil {
+		prepare(req)
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, 0, fmt.Errorf("%w: executing request: %w", ErrUpstreamDown, err)
+	}
+
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, 0, fmt.Errorf("%w: reading body: %w", ErrUpstreamDown, err)
+	}
+
+	r.archivePayload(ctx, pilotID, resp.StatusCode, body)
+
+	// The body of an error is not returned, it is usually an HTML page.
+	if err = statusError(resp.StatusCode); err != nil {
+		return nil, parseRetryAfter(resp.Header.Get("Retry-After")), fmt.Errorf(
+			"%w: status code %d", err, resp.StatusCode,
+		)
+	}
+
+	return body, 0, nil
+}
+
+// archivePayload stores the raw response if an archive is set.
+//
+// A failure to archive is logged and does not fail the request.
+func (r *requester) archivePayload(ctx context.Context, pilotID string, statusCode int, body []byte) {
+	if r.archive == nil {
+		return
+	}
+
+	if err := r.archive.WritePayload(ctx, model.Payload{
+		PilotID:    pilotID,
+		Source:     r.source,
+		StatusCode: statusCode,
+		FetchedAt:  time.Now(),
+		Content:    body,
+	}); err != nil {
+		r.logger.Error("Archiving payload", "source", r.source, "pilotID", pilotID, "error", err)
+	}
+}
+
+// parseRetryAfter returns the delay of a Retry-After header, in seconds or as an HTTP date.
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return max(time.Duration(seconds)*time.Second, 0)
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		return max(time.Until(date), 0)
+	}
+
+	return 0
+}
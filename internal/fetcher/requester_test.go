@@ -0,0 +1,220 @@
+package fetcher
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"fahy.xyz/livetrack/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/time/rate"
+)
+
+func newTestRequester(threshold int) *requester {
+	r := newRequester("test", slog.Default(), &emptyMetrics{})
+	r.backoff = backoff{maxRetries: 2, baseDelay: time.Millisecond, maxDelay: 10 * time.Millisecond}
+	r.breaker = newCircuitBreaker(threshold, time.Minute, nil)
+
+	return r
+}
+
+func TestRequester_getRetries(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if calls.Add(1) < 3 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusServiceUnavailable)
+
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	body, err := newTestRequester(5).get(t.Context(), "pilotId", server.URL, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "ok", string(body))
+	assert.Equal(t, int32(3), calls.Load())
+}
+
+func TestRequester_getGivesUp(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	requester := newTestRequester(3)
+
+	_, err := requester.get(t.Context(), "pilotId", server.URL, nil)
+	require.ErrorIs(t, err, ErrUpstreamDown)
+	assert.Equal(t, int32(3), calls.Load())
+
+	// The source keeps failing, no request is sent anymore.
+	assert.Equal(t, CircuitOpen, requester.breaker.State())
+	_, err = requester.get(t.Context(), "pilotId", server.URL, nil)
+	require.ErrorIs(t, err, ErrCircuitOpen)
+	assert.Equal(t, int32(3), calls.Load())
+}
+
+func TestRequester_getRetryAfter(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls.Add(1)
+		w.Header().Set("Retry-After", "3600")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	requester := newTestRequester(5)
+
+	// The source asks to wait longer than the maximum delay, the circuit is open.
+	_, err := requester.get(t.Context(), "pilotId", server.URL, nil)
+	require.ErrorIs(t, err, ErrRateLimited)
+	assert.Equal(t, int32(1), calls.Load())
+	assert.Equal(t, CircuitOpen, requester.breaker.State())
+}
+
+func TestRequester_getStatusErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		statusCode int
+		expected   error
+	}{
+		{statusCode: http.StatusNotFound, expected: ErrFeedNotFound},
+		{statusCode: http.StatusUnauthorized, expected: ErrFeedPrivate},
+		{statusCode: http.StatusForbidden, expected: ErrFeedPrivate},
+		{statusCode: http.StatusTooManyRequests, expected: ErrRateLimited},
+		{statusCode: http.StatusInternalServerError, expected: ErrUpstreamDown},
+		{statusCode: http.StatusBadRequest, expected: ErrUnexpectedStatus},
+	}
+
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.statusCode), func(t *testing.T) {
+			t.Parallel()
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(tt.statusCode)
+				_, _ = w.Write([]byte("<html>error page</html>"))
+			}))
+			defer server.Close()
+
+			_, err := newTestRequester(5).get(t.Context(), "pilotId", server.URL, nil)
+			require.ErrorIs(t, err, tt.expected)
+			assert.NotContains(t, err.Error(), "error page")
+		})
+	}
+}
+
+type fakeArchive struct {
+	payloads []model.Payload
+}
+
+func (a *fakeArchive) WritePayload(_ context.Context, payload model.Payload) error {
+	a.payloads = append(a.payloads, payload)
+
+	return nil
+}
+
+func TestRequester_getArchive(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte("down"))
+
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	archive := &fakeArchive{}
+	requester := newTestRequester(5)
+	requester.archive = archive
+
+	_, err := requester.get(t.Context(), "pilotId", server.URL, nil)
+	require.NoError(t, err)
+
+	// Every response is archived, including the errors.
+	require.Len(t, archive.payloads, 2)
+	assert.Equal(t, http.StatusServiceUnavailable, archive.payloads[0].StatusCode)
+	assert.Equal(t, "down", string(archive.payloads[0].Content))
+	assert.Equal(t, http.StatusOK, archive.payloads[1].StatusCode)
+	assert.Equal(t, "ok", string(archive.payloads[1].Content))
+	assert.Equal(t, "pilotId", archive.payloads[1].PilotID)
+	assert.Equal(t, "test", archive.payloads[1].Source)
+}
+
+func TestRequester_getRateLimited(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu    sync.Mutex
+		calls []time.Time
+	)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		calls = append(calls, time.Now())
+		if len(calls) == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusServiceUnavailable)
+
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	requester := newTestRequester(5)
+	requester.limiter = rate.NewLimiter(rate.Every(50*time.Millisecond), 1)
+
+	// The retry and the next page wait for the limiter, as the requests of the other pilots.
+	for range 2 {
+		_, err := requester.get(t.Context(), "pilotId", server.URL, nil)
+		require.NoError(t, err)
+	}
+
+	require.Len(t, calls, 3)
+
+	for i := 1; i < len(calls); i++ {
+		assert.GreaterOrEqual(t, calls[i].Sub(calls[i-1]), 40*time.Millisecond)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, time.Duration(0), parseRetryAfter(""))
+	assert.Equal(t, time.Duration(0), parseRetryAfter("invalid"))
+	assert.Equal(t, 120*time.Second, parseRetryAfter("120"))
+	assert.InDelta(t, time.Hour, parseRetryAfter(time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)), float64(2*time.Second))
+}
@@ -3,81 +3,144 @@ package fetcher
 import (
 	"context"
 	"fmt"
-	"io"
 	"log/slog"
-	"net/http"
 	"net/url"
 	"time"
 
 	"fahy.xyz/livetrack/internal/model"
 	"fahy.xyz/livetrack/internal/model/spot"
+	"golang.org/x/time/rate"
 )
 
+// SpotTracker is the tracker type of the spot fetcher.
+const SpotTracker = "spot"
+
 type SpotFetcher struct {
-	client  *http.Client
-	url     string
-	logger  *slog.Logger
-	metrics metrics
+	requester *requester
+	url       string
+	logger    *slog.Logger
+	metrics   metrics
 }
 
 func NewSpotFetcher(url string, logger *slog.Logger, metrics metrics) *SpotFetcher {
 	return &SpotFetcher{
-		client:  &http.Client{Timeout: HTTPTimeout},
-		url:     url,
-		logger:  logger,
-		metrics: metrics,
+		requester: newRequester(SpotTracker, logger, metrics),
+		url:       url,
+		logger:    logger,
+		metrics:   metrics,
 	}
 }
 
-func (f *SpotFetcher) Fetch(ctx context.Context, id string) ([]model.Point, error) {
-	url, err := f.createURL(id)
-	if err != nil {
-		return nil, fmt.Errorf("creating URL: %w", err)
-	}
+// TrackerType returns the tracker type of the spot fetcher.
+func (f *SpotFetcher) TrackerType() string {
+	return SpotTracker
+}
 
-	f.logger.Info("fetching", "url", url)
+// Fetch retrieves all the messages since the given time.
+//
+// The feed returns a limited number of messages per call, so the pages are
+// requested until the total count of messages is reached.
+func (f *SpotFetcher) Fetch(ctx context.Context, pilot model.Pilot, since time.Time) ([]model.Point, error) {
+	points := []model.Point{}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
-	if err != nil {
-		return nil, fmt.Errorf("creating request: %w", err)
+	for start := 0; ; {
+		response, err := f.fetchPage(ctx, pilot.ID, since, start)
+		if err != nil {
+			return nil, fmt.Errorf("fetching page starting at %d: %w", start, err)
+		}
+
+		pagePoints, err := response.ToPoints()
+		if err != nil {
+			return nil, fmt.Errorf("%w: parsing points: %w", ErrMalformedPayload, err)
+		}
+
+		points = append(points, pagePoints...)
+
+		feed := response.FeedMessageResponse
+		start += feed.Count
+
+		if feed.Count == 0 || start >= feed.TotalCount {
+			break
+		}
 	}
 
-	req.Header.Set("User-Agent", "Wget/1.13.4 (linux-gnu)")
+	f.metrics.MessageFetched(SpotTracker)
 
-	resp, err := f.client.Do(req)
+	return points, nil
+}
+
+// fetchPage retrieves the page of messages starting at the given offset.
+func (f *SpotFetcher) fetchPage(ctx context.Context, id string, since time.Time, start int) (spot.Response, error) {
+	url, err := f.createURL(id, since, start)
 	if err != nil {
-		return nil, fmt.Errorf("executing request: %w", err)
+		return spot.Response{}, fmt.Errorf("creating URL: %w", err)
 	}
 
-	defer resp.Body.Close()
+	f.logger.Info("fetching", "url", url)
 
-	body, err := io.ReadAll(resp.Body)
+	body, err := f.requester.get(ctx, id, url, nil)
 	if err != nil {
-		return nil, fmt.Errorf("reading body: %w", err)
+		return spot.Response{}, fmt.Errorf("requesting feed: %w", err)
 	}
 
-	response, err := spot.Parse(body)
+	return parseSpotResponse(body)
+}
+
+// ParsePayload converts a raw page of the feed into points.
+func (f *SpotFetcher) ParsePayload(body []byte) ([]model.Point, error) {
+	response, err := parseSpotResponse(body)
 	if err != nil {
-		return nil, fmt.Errorf("parsing body: %w", err)
+		return nil, err
 	}
 
 	points, err := response.ToPoints()
 	if err != nil {
-		return nil, fmt.Errorf("parsing points: %w", err)
+		return nil, fmt.Errorf("%w: parsing points: %w", ErrMalformedPayload, err)
 	}
 
-	f.metrics.MessageFetched("spot")
-
 	return points, nil
 }
 
-func (f *SpotFetcher) createURL(id string) (string, error) {
+// SetArchive stores the raw responses of the feed in the archive.
+func (f *SpotFetcher) SetArchive(archive Archive) {
+	f.requester.archive = archive
+}
+
+// SetRateLimit sets the minimum delay between two requests to the feed, for all the pilots, 0 to not throttle.
+func (f *SpotFetcher) SetRateLimit(interval time.Duration) {
+	f.requester.limiter = rate.NewLimiter(rate.Every(interval), 1)
+}
+
+// parseSpotResponse parses a page of the feed, returning the error of the feed if any.
+func parseSpotResponse(body []byte) (spot.Response, error) {
+	response, err := spot.Parse(body)
+	if err != nil {
+		return spot.Response{}, fmt.Errorf("%w: parsing body: %w", ErrMalformedPayload, err)
+	}
+
+	switch feedError := response.Errors.Error; feedError.Code {
+	case "", spot.ErrorCodeNoMessages:
+		return response, nil
+	case spot.ErrorCodeFeedNotFound:
+		return spot.Response{}, fmt.Errorf("%w: %s", ErrFeedNotFound, feedError.Description)
+	default:
+		return spot.Response{}, fmt.Errorf("%w: %s %s", ErrUpstreamDown, feedError.Code, feedError.Description)
+	}
+}
+
+// createURL returns the feed URL with the messages since the given time.
+//
+// The start is the offset of the first message of the page.
+func (f *SpotFetcher) createURL(id string, since time.Time, start int) (string, error) {
 	s, err := url.JoinPath(f.url, id, "message.json")
 	if err != nil {
 		return "", fmt.Errorf("joining path: %w", err)
 	}
 
-	sWithDate := fmt.Sprintf("%s?startDate=%s", s, time.Now().Format("2006-01-02T00:00:00-0000"))
+	sWithDate := fmt.Sprintf("%s?startDate=%s", s, since.UTC().Format("2006-01-02T15:04:05-0000"))
+	if start > 0 {
+		sWithDate = fmt.Sprintf("%s&start=%d", sWithDate, start)
+	}
 
 	return sWithDate, nil
 }
@@ -8,6 +8,7 @@ import (
 	"testing"
 	"time"
 
+	"fahy.xyz/livetrack/internal/model"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -20,13 +21,21 @@ func TestSpotFetcher_createURL(t *testing.T) {
 		slog.Default().With("component", "spot-fetcher"),
 		&emptyMetrics{},
 	)
-	urlA, err := fetcherA.createURL("0onlLopfoM4bG5jXvWRE8H0Obd0oMxMBq")
+	urlA, err := fetcherA.createURL("0onlLopfoM4bG5jXvWRE8H0Obd0oMxMBq", time.Date(2023, time.Month(1), 14, 7, 47, 9, 0, time.UTC), 0)
 	require.NoError(t, err)
 	assert.Equal(
 		t,
-		"https://api.findmespot.com/spot-main-web/consumer/rest-api/2.0/public/feed/0onlLopfoM4bG5jXvWRE8H0Obd0oMxMBq/message.json?startDate="+time.Now().Format("2006-01-02T00:00:00-0000"),
+		"https://api.findmespot.com/spot-main-web/consumer/rest-api/2.0/public/feed/0onlLopfoM4bG5jXvWRE8H0Obd0oMxMBq/message.json?startDate=2023-01-14T07:47:09-0000",
 		urlA,
 	)
+
+	urlB, err := fetcherA.createURL("0onlLopfoM4bG5jXvWRE8H0Obd0oMxMBq", time.Date(2023, time.Month(1), 14, 7, 47, 9, 0, time.UTC), 50)
+	require.NoError(t, err)
+	assert.Equal(
+		t,
+		"https://api.findmespot.com/spot-main-web/consumer/rest-api/2.0/public/feed/0onlLopfoM4bG5jXvWRE8H0Obd0oMxMBq/message.json?startDate=2023-01-14T07:47:09-0000&start=50",
+		urlB,
+	)
 }
 
 func TestSpotFetcher_Fetch(t *testing.T) {
@@ -43,9 +52,92 @@ func TestSpotFetcher_Fetch(t *testing.T) {
 	defer server.Close()
 
 	fetcher := NewSpotFetcher(server.URL, slog.Default().With("component", "spot-fetcher"), &emptyMetrics{})
-	res, err := fetcher.Fetch(t.Context(), "0smxuLcDXXlQkR6Uzu2HcDvp7MmW7TCLc")
+	res, err := fetcher.Fetch(t.Context(), model.Pilot{ID: "0smxuLcDXXlQkR6Uzu2HcDvp7MmW7TCLc"}, time.Now())
 	require.NoError(t, err)
 
 	assert.Equal(t, "OK", res[0].MsgType)
 	assert.InEpsilon(t, 46.45669, res[1].Latitude, 0.1)
 }
+
+func TestSpotFetcher_FetchPages(t *testing.T) {
+	t.Parallel()
+
+	requests := []string{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := r.URL.Query().Get("start")
+		requests = append(requests, start)
+
+		file := "../model/spot/testdata/response_long_page_1.json"
+		if start == "50" {
+			file = "../model/spot/testdata/response_long_page_2.json"
+		}
+
+		w.WriteHeader(http.StatusOK)
+
+		data, err := os.ReadFile(file)
+		assert.NoError(t, err)
+
+		_, _ = w.Write(data)
+	}))
+	defer server.Close()
+
+	fetcher := NewSpotFetcher(server.URL, slog.Default().With("component", "spot-fetcher"), &emptyMetrics{})
+	res, err := fetcher.Fetch(t.Context(), model.Pilot{ID: "0smxuLcDXXlQkR6Uzu2HcDvp7MmW7TCLc"}, time.Now())
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"", "50"}, requests)
+	assert.Len(t, res, 51)
+	assert.Equal(t, "UNLIMITED-TRACK", res[50].MsgType)
+}
+
+func TestSpotFetcher_FetchErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		file     string
+		expected error
+	}{
+		{file: "../model/spot/testdata/response_feed_not_found.json", expected: ErrFeedNotFound},
+		{file: "../model/garmin/testdata/feed.kml", expected: ErrMalformedPayload},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			t.Parallel()
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusOK)
+
+				data, err := os.ReadFile(tt.file)
+				assert.NoError(t, err)
+
+				_, _ = w.Write(data)
+			}))
+			defer server.Close()
+
+			fetcher := NewSpotFetcher(server.URL, slog.Default().With("component", "spot-fetcher"), &emptyMetrics{})
+			_, err := fetcher.Fetch(t.Context(), model.Pilot{ID: "0smxuLcDXXlQkR6Uzu2HcDvp7MmW7TCLc"}, time.Now())
+			require.ErrorIs(t, err, tt.expected)
+		})
+	}
+}
+
+func TestSpotFetcher_FetchNoMessages(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+
+		data, err := os.ReadFile("../model/spot/testdata/response_no_messages.json")
+		assert.NoError(t, err)
+
+		_, _ = w.Write(data)
+	}))
+	defer server.Close()
+
+	fetcher := NewSpotFetcher(server.URL, slog.Default().With("component", "spot-fetcher"), &emptyMetrics{})
+	res, err := fetcher.Fetch(t.Context(), model.Pilot{ID: "0smxuLcDXXlQkR6Uzu2HcDvp7MmW7TCLc"}, time.Now())
+	require.NoError(t, err)
+	assert.Empty(t, res)
+}
@@ -0,0 +1,113 @@
+package geo
+
+import (
+	"math"
+)
+
+// boundTolerance is the precision of the bearing of the farthest longitude of a circle, in degrees.
+const boundTolerance = 1e-9
+
+// BoundingBox is the smallest box of latitudes and longitudes holding positions, in decimal degrees.
+//
+// The boxes do not cross the antimeridian, their west is always lower than their east.
+type BoundingBox struct {
+	MinLatitude  float64
+	MinLongitude float64
+	MaxLatitude  float64
+	MaxLongitude float64
+}
+
+// NewBoundingBox returns the box of the positions, the box of the position 0, 0 if there is none.
+func NewBoundingBox(positions ...Position) BoundingBox {
+	if len(positions) == 0 {
+		return BoundingBox{}
+	}
+
+	box := BoundingBox{
+		MinLatitude:  positions[0].Latitude,
+		MinLongitude: positions[0].Longitude,
+		MaxLatitude:  positions[0].Latitude,
+		MaxLongitude: positions[0].Longitude,
+	}
+
+	for _, position := range positions[1:] {
+		box = box.Extend(position)
+	}
+
+	return box
+}
+
+// BoundingBoxAround returns the box of the circle of the radius in km around the center.
+//
+// The box holds all the longitudes if the circle goes around a pole.
+func BoundingBoxAround(center Position, radius float64) BoundingBox {
+	north := Destination(center, 0, radius)
+	south := Destination(center, 180, radius)
+
+	box := BoundingBox{
+		MinLatitude:  south.Latitude,
+		MinLongitude: -180,
+		MaxLatitude:  north.Latitude,
+		MaxLongitude: 180,
+	}
+
+	// The circle holds a pole if the radius reaches it.
+	switch {
+	case radius >= Distance(center, Position{Latitude: 90}):
+		box.MaxLatitude = 90
+
+		return box
+	case radius >= Distance(center, Position{Latitude: -90}):
+		box.MinLatitude = -90
+
+		return box
+	}
+
+	// The longitude of the destination is the largest for a bearing between the north and the south, found by
+	// a golden-section search.
+	longitude := func(bearing float64) float64 {
+		return normalizeLongitude(Destination(center, bearing, radius).Longitude - center.Longitude)
+	}
+
+	low, high := 0.0, 180.0
+	for high-low > boundTolerance {
+		left, right := high-(high-low)/math.Phi, low+(high-low)/math.Phi
+		if longitude(left) < longitude(right) {
+			low = left
+		} else {
+			high = right
+		}
+	}
+
+	bearing := (low + high) / 2
+	east := Destination(center, bearing, radius)
+	halfWidth := normalizeLongitude(east.Longitude - center.Longitude)
+	box.MinLongitude = center.Longitude - halfWidth
+	box.MaxLongitude = center.Longitude + halfWidth
+
+	return box
+}
+
+// Extend returns the box holding the box and the position.
+func (b BoundingBox) Extend(position Position) BoundingBox {
+	b.MinLatitude = math.Min(b.MinLatitude, position.Latitude)
+	b.MinLongitude = math.Min(b.MinLongitude, position.Longitude)
+	b.MaxLatitude = math.Max(b.MaxLatitude, position.Latitude)
+	b.MaxLongitude = math.Max(b.MaxLongitude, position.Longitude)
+
+	return b
+}
+
+// Contains returns true if the position is in the box, its bounds included.
+func (b BoundingBox) Contains(position Position) bool {
+	return position.Latitude >= b.MinLatitude && position.Latitude <= b.MaxLatitude &&
+		position.Longitude >= b.MinLongitude && position.Longitude <= b.MaxLongitude
+}
+
+// Center returns the position in the middle of the latitudes and longitudes of the box.
+func (b BoundingBox) Center() Position {
+	return Position{
+		Latitude:  (b.MinLatitude + b.MaxLatitude) / 2,
+		Longitude: (b.MinLongitude + b.MaxLongitude) / 2,
+	}
+}
@@ -0,0 +1,82 @@
+package geo_test
+
+import (
+	"testing"
+
+	"fahy.xyz/livetrack/internal/geo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewBoundingBox(t *testing.T) {
+	t.Parallel()
+
+	box := geo.NewBoundingBox(
+		geo.Position{Latitude: 46.45669, Longitude: 6.88411},
+		geo.Position{Latitude: 46.36871, Longitude: 7.02544},
+		geo.Position{Latitude: 46.51265, Longitude: 6.95873},
+	)
+
+	assert.Equal(t, geo.BoundingBox{MinLatitude: 46.36871, MinLongitude: 6.88411, MaxLatitude: 46.51265, MaxLongitude: 7.02544}, box)
+	assert.True(t, box.Contains(geo.Position{Latitude: 46.4, Longitude: 6.9}))
+	assert.True(t, box.Contains(geo.Position{Latitude: 46.36871, Longitude: 6.88411}))
+	assert.False(t, box.Contains(geo.Position{Latitude: 46.6, Longitude: 6.9}))
+	assert.InDelta(t, 46.44068, box.Center().Latitude, 1e-9)
+
+	assert.Equal(t, geo.BoundingBox{}, geo.NewBoundingBox())
+}
+
+func TestBoundingBoxAround(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		center geo.Position
+		radius float64
+	}{
+		{name: "alps", center: geo.Position{Latitude: 46.5, Longitude: 7.5}, radius: 10},
+		{name: "equator", center: geo.Position{}, radius: 100},
+		{name: "south", center: geo.Position{Latitude: -70, Longitude: 20}, radius: 500},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			box := geo.BoundingBoxAround(tt.center, tt.radius)
+			// The bounds are found within a few nanodegrees.
+			widened := geo.BoundingBox{
+				MinLatitude:  box.MinLatitude - 1e-8,
+				MinLongitude: box.MinLongitude - 1e-8,
+				MaxLatitude:  box.MaxLatitude + 1e-8,
+				MaxLongitude: box.MaxLongitude + 1e-8,
+			}
+
+			// The box holds the circle and touches it at its four sides.
+			var touching [4]bool
+
+			for bearing := 0.0; bearing < 360; bearing += 0.5 {
+				position := geo.Destination(tt.center, bearing, tt.radius)
+				assert.True(t, widened.Contains(position), bearing)
+
+				for i, distance := range []float64{
+					position.Latitude - box.MinLatitude,
+					box.MaxLatitude - position.Latitude,
+					position.Longitude - box.MinLongitude,
+					box.MaxLongitude - position.Longitude,
+				} {
+					touching[i] = touching[i] || distance < 1e-4
+				}
+			}
+
+			assert.Equal(t, [4]bool{true, true, true, true}, touching)
+		})
+	}
+}
+
+func TestBoundingBoxAround_Pole(t *testing.T) {
+	t.Parallel()
+
+	box := geo.BoundingBoxAround(geo.Position{Latitude: 89, Longitude: 10}, 200)
+	assert.Equal(t, geo.BoundingBox{MinLatitude: box.MinLatitude, MinLongitude: -180, MaxLatitude: 90, MaxLongitude: 180}, box)
+	assert.InDelta(t, 87.2, box.MinLatitude, 0.01)
+}
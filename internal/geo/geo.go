@@ -0,0 +1,224 @@
+// Package geo computes the geodesics on the WGS84 ellipsoid: distance, bearing and destination of a position,
+// and the bounding boxes of positions.
+//
+// The geodesics are solved with the formulas of Vincenty, accurate to the millimeter. The distances are in
+// kilometers, the bearings in degrees clockwise from the north and the positions in decimal degrees.
+package geo
+
+import (
+	"math"
+)
+
+const (
+	// semiMajorAxis is the equatorial radius of the WGS84 ellipsoid, in km.
+	semiMajorAxis = 6378.137
+	// flattening of the WGS84 ellipsoid.
+	flattening = 1 / 298.257223563
+	// semiMinorAxis is the polar radius of the WGS84 ellipsoid, in km.
+	semiMinorAxis = semiMajorAxis * (1 - flattening)
+	// meanRadius is the radius of the sphere of the fallback of the nearly antipodal positions, in km.
+	meanRadius = (2*semiMajorAxis + semiMinorAxis) / 3
+
+	// tolerance of the iterations, in radians, about 0.006 mm.
+	tolerance = 1e-12
+	// maxIterations of the inverse problem, it only fails to converge for nearly antipodal positions.
+	maxIterations = 200
+)
+
+// Position is a point on the ellipsoid, in decimal degrees.
+type Position struct {
+	Latitude  float64
+	Longitude float64
+}
+
+// Distance returns the length of the geodesic between the positions, in km.
+func Distance(from, to Position) float64 {
+	distance, _, _ := inverse(from, to)
+
+	return distance
+}
+
+// Bearing returns the initial bearing of the geodesic from a position to the other, in degrees in [0, 360).
+//
+// The bearing between two equal positions is 0.
+func Bearing(from, to Position) float64 {
+	_, bearing, _ := inverse(from, to)
+
+	return bearing
+}
+
+// FinalBearing returns the bearing of the geodesic on its arrival at the position to, in degrees in [0, 360).
+func FinalBearing(from, to Position) float64 {
+	_, _, bearing := inverse(from, to)
+
+	return bearing
+}
+
+// Destination returns the position at the distance in km from a position, along the geodesic of the initial bearing.
+func Destination(from Position, bearing, distance float64) Position {
+	return direct(from, bearing, distance)
+}
+
+// inverse returns the distance and the initial and final bearings of the geodesic between the positions.
+//
+// The nearly antipodal positions, where the iterations do not converge, are solved on a sphere.
+func inverse(from, to Position) (float64, float64, float64) {
+	lambdaDiff := radians(normalizeLongitude(to.Longitude - from.Longitude))
+	sinU1, cosU1 := reducedLatitude(from.Latitude)
+	sinU2, cosU2 := reducedLatitude(to.Latitude)
+
+	lambda := lambdaDiff
+
+	for range maxIterations {
+		sinLambda, cosLambda := math.Sincos(lambda)
+
+		sinSigma := math.Hypot(cosU2*sinLambda, cosU1*sinU2-sinU1*cosU2*cosLambda)
+		if sinSigma == 0 {
+			// Equal positions.
+			return 0, 0, 0
+		}
+
+		cosSigma := sinU1*sinU2 + cosU1*cosU2*cosLambda
+		sigma := math.Atan2(sinSigma, cosSigma)
+		sinAlpha := cosU1 * cosU2 * sinLambda / sinSigma
+		cosSqAlpha := 1 - sinAlpha*sinAlpha
+
+		// The geodesics along the equator have no midpoint.
+		cos2SigmaM := 0.0
+		if cosSqAlpha != 0 {
+			cos2SigmaM = cosSigma - 2*sinU1*sinU2/cosSqAlpha
+		}
+
+		c := flattening / 16 * cosSqAlpha * (4 + flattening*(4-3*cosSqAlpha))
+		previous := lambda
+		lambda = lambdaDiff + (1-c)*flattening*sinAlpha*
+			(sigma+c*sinSigma*(cos2SigmaM+c*cosSigma*(-1+2*cos2SigmaM*cos2SigmaM)))
+
+		if math.Abs(lambda) > math.Pi {
+			break
+		}
+
+		if math.Abs(lambda-previous) > tolerance {
+			continue
+		}
+
+		a, b := seriesCoefficients(cosSqAlpha)
+		deltaSigma := sigmaCorrection(b, sinSigma, cosSigma, cos2SigmaM)
+		distance := semiMinorAxis * a * (sigma - deltaSigma)
+
+		sinLambda, cosLambda = math.Sincos(lambda)
+		initial := math.Atan2(cosU2*sinLambda, cosU1*sinU2-sinU1*cosU2*cosLambda)
+		final := math.Atan2(cosU1*sinLambda, -sinU1*cosU2+cosU1*sinU2*cosLambda)
+
+		return distance, normalizeBearing(degrees(initial)), normalizeBearing(degrees(final))
+	}
+
+	return sphericalInverse(from, to)
+}
+
+// direct returns the destination of the geodesic.
+func direct(from Position, bearing, distance float64) Position {
+	sinAlpha1, cosAlpha1 := math.Sincos(radians(bearing))
+	sinU1, cosU1 := reducedLatitude(from.Latitude)
+
+	sigma1 := math.Atan2(sinU1/cosU1, cosAlpha1)
+	sinAlpha := cosU1 * sinAlpha1
+	cosSqAlpha := 1 - sinAlpha*sinAlpha
+	a, b := seriesCoefficients(cosSqAlpha)
+
+	sigma := distance / (semiMinorAxis * a)
+
+	var sinSigma, cosSigma, cos2SigmaM float64
+
+	for range maxIterations {
+		cos2SigmaM = math.Cos(2*sigma1 + sigma)
+		sinSigma, cosSigma = math.Sincos(sigma)
+
+		previous := sigma
+		sigma = distance/(semiMinorAxis*a) + sigmaCorrection(b, sinSigma, cosSigma, cos2SigmaM)
+
+		if math.Abs(sigma-previous) <= tolerance {
+			break
+		}
+	}
+
+	cos2SigmaM = math.Cos(2*sigma1 + sigma)
+	sinSigma, cosSigma = math.Sincos(sigma)
+
+	tmp := sinU1*sinSigma - cosU1*cosSigma*cosAlpha1
+	latitude := math.Atan2(sinU1*cosSigma+cosU1*sinSigma*cosAlpha1, (1-flattening)*math.Hypot(sinAlpha, tmp))
+	lambda := math.Atan2(sinSigma*sinAlpha1, cosU1*cosSigma-sinU1*sinSigma*cosAlpha1)
+	c := flattening / 16 * cosSqAlpha * (4 + flattening*(4-3*cosSqAlpha))
+	longitudeDiff := lambda - (1-c)*flattening*sinAlpha*
+		(sigma+c*sinSigma*(cos2SigmaM+c*cosSigma*(-1+2*cos2SigmaM*cos2SigmaM)))
+
+	return Position{
+		Latitude:  degrees(latitude),
+		Longitude: normalizeLongitude(from.Longitude + degrees(longitudeDiff)),
+	}
+}
+
+// sphericalInverse solves the inverse problem on the sphere of the mean radius, within 0.5% of the ellipsoid.
+func sphericalInverse(from, to Position) (float64, float64, float64) {
+	phi1, phi2 := radians(from.Latitude), radians(to.Latitude)
+	deltaLambda := radians(to.Longitude - from.Longitude)
+
+	haversine := math.Pow(math.Sin((phi2-phi1)/2), 2) +
+		math.Cos(phi1)*math.Cos(phi2)*math.Pow(math.Sin(deltaLambda/2), 2)
+	distance := 2 * meanRadius * math.Asin(math.Sqrt(math.Min(haversine, 1)))
+
+	bearing := func(phi1, phi2, deltaLambda float64) float64 {
+		return degrees(math.Atan2(math.Sin(deltaLambda)*math.Cos(phi2),
+			math.Cos(phi1)*math.Sin(phi2)-math.Sin(phi1)*math.Cos(phi2)*math.Cos(deltaLambda)))
+	}
+
+	initial := bearing(phi1, phi2, deltaLambda)
+	final := bearing(phi2, phi1, -deltaLambda) + 180
+
+	return distance, normalizeBearing(initial), normalizeBearing(final)
+}
+
+// reducedLatitude returns the sine and cosine of the latitude on the auxiliary sphere.
+func reducedLatitude(latitude float64) (float64, float64) {
+	u := math.Atan((1 - flattening) * math.Tan(radians(latitude)))
+
+	return math.Sincos(u)
+}
+
+// seriesCoefficients returns the coefficients A and B of the series of Vincenty.
+func seriesCoefficients(cosSqAlpha float64) (float64, float64) {
+	uSq := cosSqAlpha * (semiMajorAxis*semiMajorAxis - semiMinorAxis*semiMinorAxis) / (semiMinorAxis * semiMinorAxis)
+	a := 1 + uSq/16384*(4096+uSq*(-768+uSq*(320-175*uSq)))
+	b := uSq / 1024 * (256 + uSq*(-128+uSq*(74-47*uSq)))
+
+	return a, b
+}
+
+// sigmaCorrection returns the difference between the arc on the auxiliary sphere and the geodesic.
+func sigmaCorrection(b, sinSigma, cosSigma, cos2SigmaM float64) float64 {
+	return b * sinSigma * (cos2SigmaM + b/4*(cosSigma*(-1+2*cos2SigmaM*cos2SigmaM)-
+		b/6*cos2SigmaM*(-3+4*sinSigma*sinSigma)*(-3+4*cos2SigmaM*cos2SigmaM)))
+}
+
+func radians(degrees float64) float64 {
+	return degrees * math.Pi / 180
+}
+
+func degrees(radians float64) float64 {
+	return radians * 180 / math.Pi
+}
+
+// normalizeBearing returns the bearing in [0, 360).
+func normalizeBearing(bearing float64) float64 {
+	bearing = math.Mod(bearing, 360)
+	if bearing < 0 {
+		bearing += 360
+	}
+
+	return bearing
+}
+
+// normalizeLongitude returns the longitude in [-180, 180).
+func normalizeLongitude(longitude float64) float64 {
+	return normalizeBearing(longitude+180) - 180
+}
@@ -0,0 +1,144 @@
+package geo_test
+
+import (
+	"math"
+	"math/rand/v2"
+	"testing"
+
+	"fahy.xyz/livetrack/internal/geo"
+	"github.com/stretchr/testify/assert"
+)
+
+// sexagesimal returns the decimal degrees of degrees, minutes and seconds, negative if the degrees are negative.
+func sexagesimal(d, m, s float64) float64 {
+	if d < 0 {
+		return d - m/60 - s/3600
+	}
+
+	return d + m/60 + s/3600
+}
+
+var (
+	// Flinders Peak and Buninyong are the example of Vincenty, the geodesic is 54972.271 m long.
+	flindersPeak = geo.Position{Latitude: sexagesimal(-37, 57, 3.72030), Longitude: sexagesimal(144, 25, 29.52440)}
+	buninyong    = geo.Position{Latitude: sexagesimal(-37, 39, 10.15610), Longitude: sexagesimal(143, 55, 35.38390)}
+)
+
+func TestDistance(t *testing.T) {
+	t.Parallel()
+
+	// The reference values are computed with GeographicLib on WGS84, in km.
+	tests := []struct {
+		name     string
+		from     geo.Position
+		to       geo.Position
+		expected float64
+		bearing  float64
+	}{
+		{
+			name:     "Flinders Peak to Buninyong",
+			from:     flindersPeak,
+			to:       buninyong,
+			expected: 54.972271,
+			bearing:  sexagesimal(306, 52, 5.37),
+		},
+		{
+			name:     "JFK to LHR",
+			from:     geo.Position{Latitude: 40.6, Longitude: -73.8},
+			to:       geo.Position{Latitude: 51.6, Longitude: -0.5},
+			expected: 5551.759400,
+			bearing:  51.198883,
+		},
+		{
+			name:     "meridian quadrant",
+			from:     geo.Position{},
+			to:       geo.Position{Latitude: 90},
+			expected: 10001.965729,
+			bearing:  0,
+		},
+		{
+			name:     "equator quadrant",
+			from:     geo.Position{},
+			to:       geo.Position{Longitude: 90},
+			expected: 10018.754171,
+			bearing:  90,
+		},
+		{
+			name:     "across the antimeridian",
+			from:     geo.Position{Longitude: 179.5},
+			to:       geo.Position{Longitude: -179.5},
+			expected: 111.319491,
+			bearing:  90,
+		},
+		{
+			name:     "same position",
+			from:     flindersPeak,
+			to:       flindersPeak,
+			expected: 0,
+			bearing:  0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// Within a millimeter.
+			assert.InDelta(t, tt.expected, geo.Distance(tt.from, tt.to), 1e-6)
+			assert.InDelta(t, tt.bearing, geo.Bearing(tt.from, tt.to), 1e-6)
+		})
+	}
+}
+
+func TestFinalBearing(t *testing.T) {
+	t.Parallel()
+
+	assert.InDelta(t, sexagesimal(307, 10, 25.07), geo.FinalBearing(flindersPeak, buninyong), 1e-6)
+}
+
+func TestDestination(t *testing.T) {
+	t.Parallel()
+
+	destination := geo.Destination(flindersPeak, sexagesimal(306, 52, 5.37), 54.972271)
+	assert.InDelta(t, buninyong.Latitude, destination.Latitude, 1e-8)
+	assert.InDelta(t, buninyong.Longitude, destination.Longitude, 1e-8)
+
+	// From JFK to CDG, the example of GeographicLib.
+	destination = geo.Destination(geo.Position{Latitude: 40.63972222, Longitude: -73.77888889}, 53.5, 5850)
+	assert.InDelta(t, 49.01467, destination.Latitude, 1e-5)
+	assert.InDelta(t, 2.56106, destination.Longitude, 1e-5)
+}
+
+func TestGeodesic_Properties(t *testing.T) {
+	t.Parallel()
+
+	random := rand.New(rand.NewPCG(22, 7)) //nolint:gosec // Reproducible positions.
+
+	for range 1000 {
+		from := geo.Position{Latitude: random.Float64()*170 - 85, Longitude: random.Float64()*360 - 180}
+		bearing := random.Float64() * 360
+		distance := random.Float64() * 15000
+
+		to := geo.Destination(from, bearing, distance)
+
+		// The inverse problem solves the direct one.
+		assert.InDelta(t, distance, geo.Distance(from, to), 1e-6, "%+v %v %v", from, bearing, distance)
+		assert.InDelta(t, 0, math.Remainder(geo.Bearing(from, to)-bearing, 360), 1e-6, "%+v %v %v", from, bearing, distance)
+
+		// The distance is symmetric and the reverse bearing is the final bearing turned around.
+		assert.InDelta(t, geo.Distance(from, to), geo.Distance(to, from), 1e-6)
+		assert.InDelta(t, 0, math.Remainder(geo.Bearing(to, from)-geo.FinalBearing(from, to)-180, 360), 1e-6)
+
+		// The triangle inequality holds through any third position.
+		through := geo.Position{Latitude: random.Float64()*170 - 85, Longitude: random.Float64()*360 - 180}
+		assert.LessOrEqual(t, geo.Distance(from, to), geo.Distance(from, through)+geo.Distance(through, to)+1e-6)
+	}
+}
+
+func TestDistance_Antipodal(t *testing.T) {
+	t.Parallel()
+
+	// The nearly antipodal positions are solved on a sphere, the geodesic along the meridian is 20003.931 km long.
+	distance := geo.Distance(geo.Position{Latitude: 0.5}, geo.Position{Latitude: -0.5, Longitude: 180})
+	assert.InDelta(t, 20003.931, distance, 0.005*20003.931)
+}
@@ -20,7 +20,10 @@ const (
 
 type Prometheus struct {
 	// Fetcher
-	msgsFetchedTotal *prometheus.CounterVec
+	msgsFetchedTotal    *prometheus.CounterVec
+	requestsRetried     *prometheus.CounterVec
+	circuitBreakerState *prometheus.GaugeVec
+	fetchErrorsTotal    *prometheus.CounterVec
 	// Database manager
 	pilotsRetrievedTotal prometheus.Counter
 	tracksRetrievedTotal prometheus.Counter
@@ -70,6 +73,27 @@ func NewPrometheusMetrics(subsys string) (*Prometheus, *prometheus.Registry, err
 		Subsystem: subsys,
 	}, []string{"source"})
 
+	prom.requestsRetried = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name:      "requests_retried_total",
+		Help:      "Number of requests to trackers retried by source",
+		Namespace: Namespace,
+		Subsystem: subsys,
+	}, []string{"source"})
+
+	prom.circuitBreakerState = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name:      "circuit_breaker_state",
+		Help:      "State of the circuit breaker by source (0=closed, 1=half-open, 2=open)",
+		Namespace: Namespace,
+		Subsystem: subsys,
+	}, []string{"source"})
+
+	prom.fetchErrorsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name:      "fetch_errors_total",
+		Help:      "Number of failed fetches from trackers by source and kind of error",
+		Namespace: Namespace,
+		Subsystem: subsys,
+	}, []string{"source", "kind"})
+
 	prom.pilotsRetrievedTotal = promauto.NewCounter(prometheus.CounterOpts{
 		Name:      "pilots_retrieved_total",
 		Help:      "Number of calls to retrieve pilots from the database",
@@ -144,6 +168,9 @@ func NewPrometheusMetrics(subsys string) (*Prometheus, *prometheus.Registry, err
 		promReg.Register(collectors.NewProcessCollector(collectors.ProcessCollectorOpts{})),
 		promReg.Register(buildInfo),
 		promReg.Register(prom.msgsFetchedTotal),
+		promReg.Register(prom.requestsRetried),
+		promReg.Register(prom.circuitBreakerState),
+		promReg.Register(prom.fetchErrorsTotal),
 		promReg.Register(prom.pilotsRetrievedTotal),
 		promReg.Register(prom.tracksRetrievedTotal),
 		promReg.Register(prom.tracksWrittenTotal),
@@ -168,6 +195,18 @@ func (p *Prometheus) MessageFetched(source string) {
 	p.msgsFetchedTotal.WithLabelValues(source).Inc()
 }
 
+func (p *Prometheus) RequestRetried(source string) {
+	p.requestsRetried.WithLabelValues(source).Inc()
+}
+
+func (p *Prometheus) CircuitStateChanged(source string, state int) {
+	p.circuitBreakerState.WithLabelValues(source).Set(float64(state))
+}
+
+func (p *Prometheus) FetchFailed(source, kind string) {
+	p.fetchErrorsTotal.WithLabelValues(source, kind).Inc()
+}
+
 // Database manager.
 func (p *Prometheus) PilotRetrieved() { p.pilotsRetrievedTotal.Inc() }
 func (p *Prometheus) TrackRetrieved() { p.tracksRetrievedTotal.Inc() }
@@ -0,0 +1,91 @@
+package dataset
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// csvWriter writes a header with the names of the columns, followed by a line by row.
+type csvWriter struct {
+	writer        *csv.Writer
+	columns       []column
+	headerWritten bool
+	record        []string
+}
+
+func newCSVWriter(w io.Writer) *csvWriter {
+	cols := columns()
+
+	return &csvWriter{
+		writer:  csv.NewWriter(w),
+		columns: cols,
+		record:  make([]string, len(cols)),
+	}
+}
+
+func (c *csvWriter) Write(row Row) error {
+	if err := c.writeHeader(); err != nil {
+		return err
+	}
+
+	for i, col := range c.columns {
+		c.record[i] = formatValue(col.kind, col.value(row))
+	}
+
+	if err := c.writer.Write(c.record); err != nil {
+		return fmt.Errorf("error writing record: %w", err)
+	}
+
+	return nil
+}
+
+func (c *csvWriter) Close() error {
+	if err := c.writeHeader(); err != nil {
+		return err
+	}
+
+	c.writer.Flush()
+
+	if err := c.writer.Error(); err != nil {
+		return fmt.Errorf("error flushing csv: %w", err)
+	}
+
+	return nil
+}
+
+func (c *csvWriter) writeHeader() error {
+	if c.headerWritten {
+		return nil
+	}
+
+	c.headerWritten = true
+
+	for i, col := range c.columns {
+		c.record[i] = col.name
+	}
+
+	if err := c.writer.Write(c.record); err != nil {
+		return fmt.Errorf("error writing header: %w", err)
+	}
+
+	return nil
+}
+
+//nolint:forcetypeassert // The type of the value is given by the kind.
+func formatValue(k kind, value any) string {
+	switch k {
+	case kindTime:
+		return timeValue(value).Format(time.RFC3339Nano)
+	case kindInt:
+		return strconv.Itoa(value.(int))
+	case kindDouble:
+		return strconv.FormatFloat(value.(float64), 'f', -1, 64)
+	case kindBool:
+		return strconv.FormatBool(value.(bool))
+	default:
+		return value.(string)
+	}
+}
@@ -0,0 +1,114 @@
+// Package dataset writes the points of the tracks as tables, in CSV, for the analysis of a season.
+//
+// The rows are written as they come, a season is not kept in memory.
+package dataset
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"fahy.xyz/livetrack/internal/model"
+)
+
+// Format is the file format of a dataset.
+type Format string
+
+const (
+	FormatCSV Format = "csv"
+)
+
+var ErrUnknownFormat = errors.New("unknown dataset format")
+
+// ParseFormat returns the format of its name, the extension of the file.
+func ParseFormat(name string) (Format, error) {
+	switch Format(name) {
+	case FormatCSV:
+		return FormatCSV, nil
+	default:
+		return "", fmt.Errorf("%w: %s", ErrUnknownFormat, name)
+	}
+}
+
+// ContentType returns the media type of the files of the format.
+func (f Format) ContentType() string {
+	return "text/csv"
+}
+
+// Row is a point of the track of a pilot, with the statistics computed from the first point of the day.
+type Row struct {
+	Pilot string
+	Point *model.Point
+}
+
+// Writer writes the rows of a dataset, Close must be called to complete the file.
+//
+// Close does not close the underlying writer.
+type Writer interface {
+	Write(row Row) error
+	Close() error
+}
+
+// NewWriter returns a writer of the format to w.
+func NewWriter(format Format, w io.Writer) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVWriter(w), nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownFormat, format)
+	}
+}
+
+// kind is the type of the values of a column.
+type kind int
+
+const (
+	kindString kind = iota
+	kindTime
+	kindInt
+	kindDouble
+	kindBool
+)
+
+type column struct {
+	name  string
+	kind  kind
+	value func(row Row) any
+}
+
+// columns returns the columns of the datasets.
+//
+// The distances are in kilometers, the speeds in kilometers per hour, the vertical speed in meters per second
+// and the flight time in seconds.
+func columns() []column {
+	return []column{
+		{"pilot", kindString, func(r Row) any { return r.Pilot }},
+		{"date_time", kindTime, func(r Row) any { return r.Point.DateTime }},
+		{"latitude", kindDouble, func(r Row) any { return r.Point.Latitude }},
+		{"longitude", kindDouble, func(r Row) any { return r.Point.Longitude }},
+		{"altitude", kindInt, func(r Row) any { return r.Point.Altitude }},
+		{"msg_type", kindString, func(r Row) any { return r.Point.MsgType }},
+		{"msg_content", kindString, func(r Row) any { return r.Point.MsgContent }},
+		{"device_model", kindString, func(r Row) any { return r.Point.DeviceModel }},
+		{"battery_state", kindString, func(r Row) any { return r.Point.BatteryState }},
+		{"valid_gps_fix", kindBool, func(r Row) any { return r.Point.ValidGPSFix }},
+		{"velocity", kindDouble, func(r Row) any { return r.Point.Velocity }},
+		{"course", kindDouble, func(r Row) any { return r.Point.Course }},
+		{"emergency", kindBool, func(r Row) any { return r.Point.Emergency }},
+		{"outlier", kindString, func(r Row) any { return string(r.Point.Outlier) }},
+		{"flight_time", kindDouble, func(r Row) any { return r.Point.FlightTime.Seconds() }},
+		{"take_off_dist", kindDouble, func(r Row) any { return r.Point.TakeOffDist }},
+		{"cum_dist", kindDouble, func(r Row) any { return r.Point.CumDist }},
+		{"avg_speed", kindDouble, func(r Row) any { return r.Point.AvgSpeed }},
+		{"leg_dist", kindDouble, func(r Row) any { return r.Point.LegDist }},
+		{"leg_speed", kindDouble, func(r Row) any { return r.Point.LegSpeed }},
+		{"vertical_speed", kindDouble, func(r Row) any { return r.Point.VerticalSpeed }},
+		{"glide_ratio", kindDouble, func(r Row) any { return r.Point.GlideRatio }},
+	}
+}
+
+// timeValue returns the time in UTC, the precision of the datasets is the millisecond.
+func timeValue(value any) time.Time {
+	return value.(time.Time).UTC().Truncate(time.Millisecond) //nolint:forcetypeassert // Checked by the kind.
+}
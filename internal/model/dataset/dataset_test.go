@@ -0,0 +1,99 @@
+package dataset_test
+
+import (
+	"bytes"
+	"encoding/csv"
+	"testing"
+	"time"
+
+	"fahy.xyz/livetrack/internal/model"
+	"fahy.xyz/livetrack/internal/model/dataset"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func rows() []dataset.Row {
+	start := time.Date(2023, time.Month(8), 22, 8, 0, 0, 0, time.UTC)
+	points := model.ComputeStatistics([]model.Point{
+		{DateTime: start, Latitude: 46.45669, Longitude: 6.88411, Altitude: 479, MsgType: "UNLIMITED-TRACK"},
+		{
+			DateTime:    start.Add(5 * time.Minute),
+			Latitude:    46.45549,
+			Longitude:   6.8854,
+			Altitude:    512,
+			MsgType:     model.MsgTypeOK,
+			MsgContent:  "Pilot has landed, safely",
+			ValidGPSFix: true,
+		},
+	})
+
+	return []dataset.Row{{Pilot: "Cassian", Point: &points[0]}, {Pilot: "Cassian", Point: &points[1]}}
+}
+
+func TestParseFormat(t *testing.T) {
+	t.Parallel()
+
+	format, err := dataset.ParseFormat("csv")
+	require.NoError(t, err)
+	assert.Equal(t, dataset.FormatCSV, format)
+	assert.Equal(t, "text/csv", format.ContentType())
+
+	// Parquet is not written until a maintained writer is used.
+	_, err = dataset.ParseFormat("parquet")
+	require.ErrorIs(t, err, dataset.ErrUnknownFormat)
+
+	_, err = dataset.ParseFormat("xlsx")
+	require.ErrorIs(t, err, dataset.ErrUnknownFormat)
+}
+
+func TestNewWriter_CSV(t *testing.T) {
+	t.Parallel()
+
+	var buffer bytes.Buffer
+
+	writer, err := dataset.NewWriter(dataset.FormatCSV, &buffer)
+	require.NoError(t, err)
+
+	for _, row := range rows() {
+		require.NoError(t, writer.Write(row))
+	}
+
+	require.NoError(t, writer.Close())
+
+	records, err := csv.NewReader(&buffer).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 3)
+
+	header := records[0]
+	assert.Equal(t, []string{"pilot", "date_time", "latitude", "longitude", "altitude"}, header[:5])
+	assert.Contains(t, header, "leg_speed")
+	assert.Contains(t, header, "glide_ratio")
+
+	record := make(map[string]string, len(header))
+	for i, name := range header {
+		record[name] = records[2][i]
+	}
+
+	assert.Equal(t, "Cassian", record["pilot"])
+	assert.Equal(t, "2023-08-22T08:05:00Z", record["date_time"])
+	assert.Equal(t, "512", record["altitude"])
+	assert.Equal(t, "Pilot has landed, safely", record["msg_content"])
+	assert.Equal(t, "true", record["valid_gps_fix"])
+	assert.Equal(t, "300", record["flight_time"])
+	assert.Empty(t, record["outlier"])
+	assert.NotEqual(t, "0", record["cum_dist"])
+}
+
+func TestNewWriter_CSVEmpty(t *testing.T) {
+	t.Parallel()
+
+	var buffer bytes.Buffer
+
+	writer, err := dataset.NewWriter(dataset.FormatCSV, &buffer)
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+
+	records, err := csv.NewReader(&buffer).ReadAll()
+	require.NoError(t, err)
+	assert.Len(t, records, 1)
+}
@@ -0,0 +1,124 @@
+package model
+
+// Outlier is the reason a point is flagged as a bad fix of the tracker.
+type Outlier string
+
+const (
+	// OutlierSpeedJump is a point too far from the previous valid point to be reached in time.
+	OutlierSpeedJump Outlier = "speedJump"
+	// OutlierDuplicate is a tracking point at the same position and altitude as the previous valid point.
+	OutlierDuplicate Outlier = "duplicate"
+	// OutlierAltitude is a point with an altitude out of range, or 0 after a point with an altitude.
+	OutlierAltitude Outlier = "invalidAltitude"
+)
+
+const (
+	// defaultMaxSpeed is the fastest ground speed of a pilot, faster than a glider or a car, in km/h.
+	defaultMaxSpeed = 400.0
+	// defaultMinAltitude is the lowest altitude of a point, below the Dead Sea, in meters.
+	defaultMinAltitude = -500
+	// defaultMaxAltitude is the highest altitude of a point, above the Himalaya, in meters.
+	defaultMaxAltitude = 9000
+)
+
+// FilterConfig holds the limits of the valid points.
+type FilterConfig struct {
+	// MaxSpeed is the fastest ground speed from the previous valid point, in km/h.
+	MaxSpeed float64
+	// MinAltitude and MaxAltitude are the range of the valid altitudes, in meters.
+	MinAltitude int
+	MaxAltitude int
+}
+
+// DefaultFilterConfig returns the limits of a pilot, who can drive or fly but not faster than a car on a highway.
+func DefaultFilterConfig() FilterConfig {
+	return FilterConfig{
+		MaxSpeed:    defaultMaxSpeed,
+		MinAltitude: defaultMinAltitude,
+		MaxAltitude: defaultMaxAltitude,
+	}
+}
+
+// IsOutlier returns true if the point is flagged as a bad fix.
+func (p *Point) IsOutlier() bool {
+	return p.Outlier != ""
+}
+
+// FlagOutliers returns a copy of the points sorted by time, with the bad fixes flagged rather than removed.
+//
+// Each point is checked against the previous valid point, the emergencies are never flagged. The first point is
+// a speed jump if the two next points are close to each other but not to it, so a bad first fix does not flag
+// the whole track.
+func FlagOutliers(points []Point, config FilterConfig) []Point {
+	flagged := make([]Point, len(points))
+	copy(flagged, points)
+
+	last := -1
+
+	for i := range flagged {
+		point := &flagged[i]
+		point.Outlier = ""
+
+		// The emergencies are shown even with a bad fix, without being the reference of the next points.
+		if point.IsEmergency() {
+			continue
+		}
+
+		switch {
+		case point.Altitude < config.MinAltitude || point.Altitude > config.MaxAltitude:
+			point.Outlier = OutlierAltitude
+		case last < 0:
+			if i+2 < len(flagged) && config.isSpeedJump(*point, flagged[i+1]) &&
+				!config.isSpeedJump(flagged[i+1], flagged[i+2]) {
+				point.Outlier = OutlierSpeedJump
+			}
+		case point.Altitude == 0 && flagged[last].Altitude != 0:
+			point.Outlier = OutlierAltitude
+		case isDuplicate(flagged[last], *point):
+			point.Outlier = OutlierDuplicate
+		case config.isSpeedJump(flagged[last], *point):
+			point.Outlier = OutlierSpeedJump
+		}
+
+		if !point.IsOutlier() {
+			last = i
+		}
+	}
+
+	return flagged
+}
+
+// WithoutOutliers returns the points which are not flagged as bad fixes.
+func WithoutOutliers(points []Point) []Point {
+	valid := make([]Point, 0, len(points))
+
+	for _, point := range points {
+		if !point.IsOutlier() {
+			valid = append(valid, point)
+		}
+	}
+
+	return valid
+}
+
+// isSpeedJump returns true if the point cannot be reached from the previous one at the maximum speed.
+func (c FilterConfig) isSpeedJump(previous, point Point) bool {
+	distance := previous.DistanceTo(point)
+
+	elapsed := point.DateTime.Sub(previous.DateTime)
+	if elapsed <= 0 {
+		return distance > 0
+	}
+
+	return distance/elapsed.Hours() > c.MaxSpeed
+}
+
+// isDuplicate returns true if the point is a tracking point repeating the position of the previous one.
+//
+// The messages are kept, the pilot often sends OK from the position of the last tracking point.
+func isDuplicate(previous, point Point) bool {
+	return !point.IsMessage() &&
+		point.Latitude == previous.Latitude &&
+		point.Longitude == previous.Longitude &&
+		point.Altitude == previous.Altitude
+}
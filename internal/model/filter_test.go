@@ -0,0 +1,137 @@
+package model_test
+
+import (
+	"testing"
+	"time"
+
+	"fahy.xyz/livetrack/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// track returns a glide of 10 points heading north at 30 km/h, a point by minute, changed by edit.
+func track(edit func(points []model.Point)) []model.Point {
+	start := time.Date(2023, time.Month(7), 22, 11, 0, 0, 0, time.UTC)
+	points := generateDay(start, []phase{{minutes: 9, speed: 30, verticalSpeed: -1}})
+	edit(points)
+
+	return points
+}
+
+func TestFlagOutliers(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		points []model.Point
+		// Expected outliers by index.
+		outliers map[int]model.Outlier
+	}{
+		{
+			name:     "valid track",
+			points:   track(func([]model.Point) {}),
+			outliers: map[int]model.Outlier{},
+		},
+		{
+			name:     "point hundreds of kilometers off",
+			points:   track(func(points []model.Point) { points[4].Latitude += 3 }),
+			outliers: map[int]model.Outlier{4: model.OutlierSpeedJump},
+		},
+		{
+			name:     "first point off",
+			points:   track(func(points []model.Point) { points[0].Longitude -= 2 }),
+			outliers: map[int]model.Outlier{0: model.OutlierSpeedJump},
+		},
+		{
+			name:     "altitude 0",
+			points:   track(func(points []model.Point) { points[5].Altitude = 0 }),
+			outliers: map[int]model.Outlier{5: model.OutlierAltitude},
+		},
+		{
+			name:     "altitude out of range",
+			points:   track(func(points []model.Point) { points[2].Altitude = 12000 }),
+			outliers: map[int]model.Outlier{2: model.OutlierAltitude},
+		},
+		{
+			name: "tracker without altitude",
+			points: track(func(points []model.Point) {
+				for i := range points {
+					points[i].Altitude = 0
+				}
+			}),
+			outliers: map[int]model.Outlier{},
+		},
+		{
+			name: "duplicate positions",
+			points: track(func(points []model.Point) {
+				points[3].Latitude, points[3].Altitude = points[2].Latitude, points[2].Altitude
+				points[4].Latitude, points[4].Altitude = points[2].Latitude, points[2].Altitude
+			}),
+			outliers: map[int]model.Outlier{3: model.OutlierDuplicate, 4: model.OutlierDuplicate},
+		},
+		{
+			name: "message at the last position",
+			points: track(func(points []model.Point) {
+				points[9].Latitude, points[9].Altitude = points[8].Latitude, points[8].Altitude
+				points[9].MsgType = model.MsgTypeOK
+			}),
+			outliers: map[int]model.Outlier{},
+		},
+		{
+			name: "emergency off",
+			points: track(func(points []model.Point) {
+				points[6].Latitude += 3
+				points[6].Emergency = true
+			}),
+			// The next points are checked against the point before the emergency.
+			outliers: map[int]model.Outlier{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			flagged := model.FlagOutliers(tt.points, model.DefaultFilterConfig())
+			require.Len(t, flagged, len(tt.points))
+
+			for i, point := range flagged {
+				assert.Equal(t, tt.outliers[i], point.Outlier, i)
+			}
+
+			assert.Len(t, model.WithoutOutliers(flagged), len(tt.points)-len(tt.outliers))
+		})
+	}
+}
+
+func TestFlagOutliers_MaxSpeed(t *testing.T) {
+	t.Parallel()
+
+	// A leg of 30 km/h is too fast for a hike.
+	config := model.DefaultFilterConfig()
+	config.MaxSpeed = 20
+
+	points := model.FlagOutliers(track(func([]model.Point) {}), config)
+	assert.Empty(t, points[0].Outlier)
+	assert.Equal(t, model.OutlierSpeedJump, points[1].Outlier)
+}
+
+func TestComputeStatistics_Outliers(t *testing.T) {
+	t.Parallel()
+
+	valid := model.ComputeStatistics(track(func([]model.Point) {}))
+	points := model.ComputeStatistics(model.FlagOutliers(
+		track(func(points []model.Point) {
+			points[0].Longitude -= 2
+			points[4].Latitude += 3
+		}),
+		model.DefaultFilterConfig(),
+	))
+
+	// The outliers have no statistics and are skipped by the statistics of the next points.
+	assert.Zero(t, points[4].CumDist)
+	assert.Zero(t, points[4].LegSpeed)
+	assert.InDelta(t, 2*valid[5].LegDist, points[5].LegDist, 0.0001)
+	assert.InDelta(t, valid[9].CumDist-valid[1].CumDist, points[9].CumDist, 0.0001)
+	assert.Equal(t, 8*time.Minute, points[9].FlightTime)
+}
@@ -0,0 +1,84 @@
+// Package flightlog parses the flight logs uploaded after the flights, in the IGC or GPX format.
+package flightlog
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"fahy.xyz/livetrack/internal/model"
+	"fahy.xyz/livetrack/internal/model/gpx"
+	"fahy.xyz/livetrack/internal/model/igc"
+)
+
+var ErrUnknownFormat = errors.New("unknown flight log format")
+
+const (
+	FormatIGC = "igc"
+	FormatGPX = "gpx"
+)
+
+// DetectFormat returns the format of the flight log from its extension, or from its content
+// if the extension is unknown.
+func DetectFormat(filename string, content []byte) (string, error) {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".igc":
+		return FormatIGC, nil
+	case ".gpx":
+		return FormatGPX, nil
+	}
+
+	content = bytes.TrimSpace(content)
+
+	switch {
+	case bytes.HasPrefix(content, []byte("<")):
+		return FormatGPX, nil
+	// The first record of an IGC file is the A record of the manufacturer.
+	case bytes.HasPrefix(content, []byte("A")):
+		return FormatIGC, nil
+	default:
+		return "", fmt.Errorf("%w: %s", ErrUnknownFormat, filename)
+	}
+}
+
+// Parse parses the flight log into points sorted by time.
+//
+// The points at the same time as the previous one are dropped, a track has one point by second.
+func Parse(filename string, content []byte) ([]model.Point, error) {
+	format, err := DetectFormat(filename, content)
+	if err != nil {
+		return nil, err
+	}
+
+	var points []model.Point
+
+	switch format {
+	case FormatIGC:
+		flight, err := igc.Parse(content)
+		if err != nil {
+			return nil, fmt.Errorf("parsing igc: %w", err)
+		}
+
+		points = flight.ToPoints()
+	case FormatGPX:
+		file, err := gpx.Parse(content)
+		if err != nil {
+			return nil, fmt.Errorf("parsing gpx: %w", err)
+		}
+
+		if points, err = file.ToPoints(); err != nil {
+			return nil, fmt.Errorf("converting gpx: %w", err)
+		}
+	}
+
+	slices.SortStableFunc(points, func(a, b model.Point) int {
+		return a.DateTime.Compare(b.DateTime)
+	})
+
+	return slices.CompactFunc(points, func(a, b model.Point) bool {
+		return a.DateTime.Equal(b.DateTime)
+	}), nil
+}
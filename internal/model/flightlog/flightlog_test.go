@@ -0,0 +1,75 @@
+package flightlog_test
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"fahy.xyz/livetrack/internal/model/flightlog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectFormat(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		filename string
+		content  string
+		expected string
+		err      error
+	}{
+		{name: "igc extension", filename: "2023-08-23-XCT-MGI-01.IGC", expected: flightlog.FormatIGC},
+		{name: "gpx extension", filename: "flight.gpx", expected: flightlog.FormatGPX},
+		{name: "igc content", filename: "upload", content: "AXCTABCDEF\r\n", expected: flightlog.FormatIGC},
+		{name: "gpx content", filename: "upload", content: "\n<?xml version=\"1.0\"?>", expected: flightlog.FormatGPX},
+		{name: "unknown", filename: "flight.kml", content: "{}", err: flightlog.ErrUnknownFormat},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			format, err := flightlog.DetectFormat(tt.filename, []byte(tt.content))
+			require.ErrorIs(t, err, tt.err)
+			assert.Equal(t, tt.expected, format)
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	content, err := os.ReadFile("../igc/testdata/flight.igc")
+	require.NoError(t, err)
+
+	points, err := flightlog.Parse("flight.igc", content)
+	require.NoError(t, err)
+	require.Len(t, points, 5)
+	assert.Equal(t, time.Date(2023, time.Month(8), 23, 10, 16, 45, 0, time.UTC), points[0].DateTime)
+
+	content, err = os.ReadFile("../gpx/testdata/flight.gpx")
+	require.NoError(t, err)
+
+	points, err = flightlog.Parse("flight.gpx", content)
+	require.NoError(t, err)
+	require.Len(t, points, 3)
+}
+
+func TestParse_Duplicates(t *testing.T) {
+	t.Parallel()
+
+	points, err := flightlog.Parse("flight.gpx", []byte(
+		`<gpx><trk>`+
+			`<trkseg><trkpt lat="46.6" lon="7.2"><time>2023-08-23T10:16:46Z</time></trkpt></trkseg>`+
+			`<trkseg><trkpt lat="46.5" lon="7.1"><time>2023-08-23T10:16:45Z</time></trkpt></trkseg>`+
+			`<trkseg><trkpt lat="46.4" lon="7.0"><time>2023-08-23T10:16:45Z</time></trkpt></trkseg>`+
+			`</trk></gpx>`,
+	))
+	require.NoError(t, err)
+	require.Len(t, points, 2)
+	assert.Equal(t, time.Date(2023, time.Month(8), 23, 10, 16, 45, 0, time.UTC), points[0].DateTime)
+	assert.InDelta(t, 46.5, points[0].Latitude, 0.000001)
+	assert.InDelta(t, 46.6, points[1].Latitude, 0.000001)
+}
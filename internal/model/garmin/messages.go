@@ -55,13 +55,29 @@ func (r *Document) ToPoints() ([]model.Point, error) {
 			return nil, fmt.Errorf("error parsing altitude %s: %w", placemark.getField("Elevation"), err)
 		}
 
+		velocity, err := parseMeasure(placemark.getField("Velocity"))
+		if err != nil {
+			return nil, fmt.Errorf("error parsing velocity %s: %w", placemark.getField("Velocity"), err)
+		}
+
+		course, err := parseMeasure(placemark.getField("Course"))
+		if err != nil {
+			return nil, fmt.Errorf("error parsing course %s: %w", placemark.getField("Course"), err)
+		}
+
 		points = append(points, model.Point{
-			DateTime:   dateTime,
-			Latitude:   latitude,
-			Longitude:  longitude,
-			Altitude:   elevation,
-			MsgType:    placemark.getField("Event"),
-			MsgContent: placemark.getField("Text"),
+			DateTime:    dateTime,
+			Latitude:    latitude,
+			Longitude:   longitude,
+			Altitude:    elevation,
+			MsgType:     placemark.getField("Event"),
+			MsgContent:  placemark.getField("Text"),
+			DeviceModel: placemark.getField("Device Type"),
+			IMEI:        placemark.getField("IMEI"),
+			ValidGPSFix: placemark.getBoolField("Valid GPS Fix"),
+			Velocity:    velocity,
+			Course:      course,
+			Emergency:   placemark.getBoolField("In Emergency"),
 		})
 	}
 
@@ -78,10 +94,32 @@ func (p *Placemark) getField(field string) string {
 	return ""
 }
 
+// getBoolField returns true if the field is "True", the values of the feed are capitalized.
+func (p *Placemark) getBoolField(field string) bool {
+	return strings.EqualFold(p.getField(field), "true")
+}
+
+// parseMeasure parses the value of a measure followed by its unit, e.g. "37.8 km/h".
+//
+// An empty measure is parsed as zero.
+func parseMeasure(measure string) (float64, error) {
+	fields := strings.Fields(measure)
+	if len(fields) == 0 {
+		return 0, nil
+	}
+
+	value, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return 0, fmt.Errorf("parsing value: %w", err)
+	}
+
+	return value, nil
+}
+
 func Parse(content []byte) (Document, error) {
 	var document Document
 	if err := xml.Unmarshal(content, &document); err != nil {
-		return Document{}, fmt.Errorf("error parsing message %s: %w", string(content), err)
+		return Document{}, fmt.Errorf("error parsing message: %w", err)
 	}
 
 	return document, nil
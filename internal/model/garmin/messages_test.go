@@ -42,6 +42,31 @@ func TestMessagesToPoint(t *testing.T) {
 	assert.InEpsilon(t, 7.206108, points[1].Longitude, 0.1)
 	assert.Equal(t, time.Date(2023, time.Month(8), 23, 10, 26, 45, 0, time.UTC), points[1].DateTime)
 	assert.Equal(t, time.Date(2023, time.Month(8), 23, 10, 36, 45, 0, time.UTC), points[2].DateTime)
+	assert.Equal(t, "inReach Mini", points[0].DeviceModel)
+	assert.Equal(t, "300434032535370", points[0].IMEI)
+	assert.True(t, points[0].ValidGPSFix)
+	assert.Empty(t, points[0].BatteryState)
+	assert.InDelta(t, 0.0, points[0].Velocity, 0.01)
+	assert.InDelta(t, 112.5, points[0].Course, 0.01)
+	assert.False(t, points[0].Emergency)
+}
+
+func TestEmergencyMessagesToPoint(t *testing.T) {
+	t.Parallel()
+
+	content, err := os.ReadFile("testdata/feed_emergency.kml")
+	require.NoError(t, err)
+
+	document, err := garmin.Parse(content)
+	require.NoError(t, err)
+
+	points, err := document.ToPoints()
+	require.NoError(t, err)
+	require.Len(t, points, 1)
+	assert.True(t, points[0].Emergency)
+	assert.True(t, points[0].IsEmergency())
+	assert.InDelta(t, 12.5, points[0].Velocity, 0.01)
+	assert.Equal(t, "SOS declared from device.", points[0].MsgType)
 }
 
 func TestMessagesParse(t *testing.T) {
@@ -0,0 +1,87 @@
+// Package geojson converts the tracks into GeoJSON feature collections (RFC 7946).
+package geojson
+
+import (
+	"slices"
+
+	"fahy.xyz/livetrack/internal/model"
+)
+
+const (
+	// ContentType is the media type of the GeoJSON documents.
+	ContentType = "application/geo+json"
+
+	typeFeatureCollection = "FeatureCollection"
+	typeFeature           = "Feature"
+	typeLineString        = "LineString"
+	typePoint             = "Point"
+)
+
+type FeatureCollection struct {
+	Type     string    `json:"type"`
+	Features []Feature `json:"features"`
+}
+
+type Feature struct {
+	Type       string     `json:"type"`
+	Geometry   Geometry   `json:"geometry"`
+	Properties Properties `json:"properties"`
+}
+
+// Geometry is a LineString or a Point, the positions are [longitude, latitude, altitude].
+type Geometry struct {
+	Type        string `json:"type"`
+	Coordinates any    `json:"coordinates"`
+}
+
+// Properties are the pilot of the feature and, for a Point, the fix with its statistics.
+type Properties struct {
+	Pilot string `json:"pilot"`
+	*model.Point
+}
+
+// FromTracks builds a collection with a LineString feature by pilot, followed by a Point feature by fix.
+//
+// The key of the map is the name of the pilot, the pilots are sorted by name and the ones without point are skipped.
+func FromTracks(tracks map[string][]model.Point) FeatureCollection {
+	collection := FeatureCollection{Type: typeFeatureCollection, Features: []Feature{}}
+
+	pilots := make([]string, 0, len(tracks))
+	for pilot := range tracks {
+		pilots = append(pilots, pilot)
+	}
+
+	slices.Sort(pilots)
+
+	for _, pilot := range pilots {
+		points := tracks[pilot]
+		if len(points) == 0 {
+			continue
+		}
+
+		line := make([][]float64, 0, len(points))
+		for _, point := range points {
+			line = append(line, position(point))
+		}
+
+		collection.Features = append(collection.Features, Feature{
+			Type:       typeFeature,
+			Geometry:   Geometry{Type: typeLineString, Coordinates: line},
+			Properties: Properties{Pilot: pilot},
+		})
+
+		for i := range points {
+			collection.Features = append(collection.Features, Feature{
+				Type:       typeFeature,
+				Geometry:   Geometry{Type: typePoint, Coordinates: position(points[i])},
+				Properties: Properties{Pilot: pilot, Point: &points[i]},
+			})
+		}
+	}
+
+	return collection
+}
+
+func position(point model.Point) []float64 {
+	return []float64{point.Longitude, point.Latitude, float64(point.Altitude)}
+}
@@ -0,0 +1,66 @@
+package geojson_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"fahy.xyz/livetrack/internal/model"
+	"fahy.xyz/livetrack/internal/model/geojson"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFromTracks(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2023, time.Month(8), 22, 8, 0, 0, 0, time.UTC)
+	points := model.ComputeStatistics([]model.Point{
+		{DateTime: start, Latitude: 46.45669, Longitude: 6.88411, Altitude: 479, MsgType: "UNLIMITED-TRACK"},
+		{DateTime: start.Add(5 * time.Minute), Latitude: 46.45549, Longitude: 6.8854, Altitude: 512, MsgType: model.MsgTypeOK},
+	})
+
+	collection := geojson.FromTracks(map[string][]model.Point{"Moff": points, "Cassian": {}})
+	assert.Equal(t, "FeatureCollection", collection.Type)
+	require.Len(t, collection.Features, 3)
+
+	line := collection.Features[0]
+	assert.Equal(t, "LineString", line.Geometry.Type)
+	assert.Equal(t, [][]float64{{6.88411, 46.45669, 479}, {6.8854, 46.45549, 512}}, line.Geometry.Coordinates)
+	assert.Equal(t, "Moff", line.Properties.Pilot)
+	assert.Nil(t, line.Properties.Point)
+
+	fix := collection.Features[2]
+	assert.Equal(t, "Point", fix.Geometry.Type)
+	assert.Equal(t, []float64{6.8854, 46.45549, 512}, fix.Geometry.Coordinates)
+	assert.Equal(t, model.MsgTypeOK, fix.Properties.MsgType)
+	assert.Positive(t, fix.Properties.CumDist)
+
+	content, err := json.Marshal(collection)
+	require.NoError(t, err)
+
+	var decoded map[string]any
+	require.NoError(t, json.Unmarshal(content, &decoded))
+
+	features, ok := decoded["features"].([]any)
+	require.True(t, ok)
+
+	// The properties of the fix are flattened, the track only has the pilot.
+	assert.Equal(t, map[string]any{"pilot": "Moff"}, features[0].(map[string]any)["properties"])
+
+	properties, ok := features[2].(map[string]any)["properties"].(map[string]any)
+	require.True(t, ok)
+	assert.Equal(t, "Moff", properties["pilot"])
+	assert.Equal(t, "OK", properties["msgType"])
+	assert.InDelta(t, 512, properties["altitude"], 0.1)
+	assert.Contains(t, properties, "cumDist")
+	assert.Contains(t, properties, "legSpeed")
+}
+
+func TestFromTracks_Empty(t *testing.T) {
+	t.Parallel()
+
+	content, err := json.Marshal(geojson.FromTracks(map[string][]model.Point{}))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"type":"FeatureCollection","features":[]}`, string(content))
+}
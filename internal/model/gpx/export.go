@@ -0,0 +1,95 @@
+package gpx
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"slices"
+
+	"fahy.xyz/livetrack/internal/model"
+)
+
+const (
+	Namespace = "http://www.topografix.com/GPX/1/1"
+	Version   = "1.1"
+	Creator   = "livetrack"
+	// ContentType is the media type of the GPX files.
+	ContentType = "application/gpx+xml"
+)
+
+// FromTracks builds a GPX file with a track by pilot, the messages of the pilots are added as waypoints.
+//
+// The key of the map is the name of the pilot, the tracks are sorted by name and the pilots without point are skipped.
+func FromTracks(name string, tracks map[string][]model.Point) GPX {
+	gpx := GPX{
+		Namespace: Namespace,
+		Version:   Version,
+		Creator:   Creator,
+		Metadata:  &Metadata{Name: name},
+	}
+
+	pilots := make([]string, 0, len(tracks))
+	for pilot := range tracks {
+		pilots = append(pilots, pilot)
+	}
+
+	slices.Sort(pilots)
+
+	for _, pilot := range pilots {
+		points := tracks[pilot]
+		if len(points) == 0 {
+			continue
+		}
+
+		segment := Segment{Points: make([]TrackPoint, 0, len(points))}
+
+		for _, point := range points {
+			elevation := float64(point.Altitude)
+			dateTime := point.DateTime.UTC()
+
+			segment.Points = append(segment.Points, TrackPoint{
+				Latitude:  point.Latitude,
+				Longitude: point.Longitude,
+				Elevation: &elevation,
+				Time:      &dateTime,
+			})
+
+			if point.IsMessage() {
+				gpx.Waypoints = append(gpx.Waypoints, Waypoint{
+					Latitude:    point.Latitude,
+					Longitude:   point.Longitude,
+					Elevation:   &elevation,
+					Time:        &dateTime,
+					Name:        fmt.Sprintf("%s %s", pilot, point.MsgType),
+					Description: point.MsgContent,
+					Type:        point.MsgType,
+				})
+			}
+		}
+
+		gpx.Tracks = append(gpx.Tracks, Track{Name: pilot, Segments: []Segment{segment}})
+
+		// The time of the file is the one of the first point.
+		if start := points[0].DateTime.UTC(); gpx.Metadata.Time == nil || start.Before(*gpx.Metadata.Time) {
+			gpx.Metadata.Time = &start
+		}
+	}
+
+	return gpx
+}
+
+// Write writes the GPX file with its XML header.
+func (g *GPX) Write(w io.Writer) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return fmt.Errorf("error writing header: %w", err)
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+
+	if err := encoder.Encode(g); err != nil {
+		return fmt.Errorf("error encoding gpx: %w", err)
+	}
+
+	return nil
+}
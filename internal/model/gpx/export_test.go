@@ -0,0 +1,63 @@
+package gpx_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"fahy.xyz/livetrack/internal/model"
+	"fahy.xyz/livetrack/internal/model/gpx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFromTracks(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2023, time.Month(8), 22, 8, 0, 0, 0, time.UTC)
+	tracks := map[string][]model.Point{
+		"Moff": {
+			{DateTime: start, Latitude: 46.45669, Longitude: 6.88411, Altitude: 479, MsgType: "UNLIMITED-TRACK"},
+			{
+				DateTime:   start.Add(5 * time.Minute),
+				Latitude:   46.45549,
+				Longitude:  6.8854,
+				Altitude:   512,
+				MsgType:    model.MsgTypeOK,
+				MsgContent: "Pilot has landed safely",
+			},
+		},
+		"Bix": {
+			{DateTime: start.Add(-time.Hour), Latitude: 46.6, Longitude: 7.2, Altitude: 1600, MsgType: model.MsgTypeTrack},
+		},
+		"Cassian": {},
+	}
+
+	file := gpx.FromTracks("2023-08-22", tracks)
+	assert.Equal(t, gpx.Version, file.Version)
+	assert.Equal(t, start.Add(-time.Hour), *file.Metadata.Time)
+	require.Len(t, file.Tracks, 2)
+	assert.Equal(t, "Bix", file.Tracks[0].Name)
+	assert.Equal(t, "Moff", file.Tracks[1].Name)
+	require.Len(t, file.Waypoints, 1)
+	assert.Equal(t, "Moff OK", file.Waypoints[0].Name)
+	assert.Equal(t, "Pilot has landed safely", file.Waypoints[0].Description)
+	assert.Equal(t, model.MsgTypeOK, file.Waypoints[0].Type)
+
+	var buffer bytes.Buffer
+	require.NoError(t, file.Write(&buffer))
+	assert.Contains(t, buffer.String(), `<?xml version="1.0" encoding="UTF-8"?>`)
+	assert.Contains(t, buffer.String(), `<gpx xmlns="http://www.topografix.com/GPX/1/1" version="1.1" creator="livetrack">`)
+	assert.Contains(t, buffer.String(), `<time>2023-08-22T08:05:00Z</time>`)
+
+	// The file written is parsed back into the same points.
+	parsed, err := gpx.Parse(buffer.Bytes())
+	require.NoError(t, err)
+
+	points, err := parsed.ToPoints()
+	require.NoError(t, err)
+	require.Len(t, points, 3)
+	assert.Equal(t, start.Add(-time.Hour), points[0].DateTime)
+	assert.Equal(t, 512, points[2].Altitude)
+	assert.InDelta(t, 46.45549, points[2].Latitude, 0.000001)
+}
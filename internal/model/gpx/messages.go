@@ -0,0 +1,106 @@
+package gpx
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"fahy.xyz/livetrack/internal/model"
+)
+
+var (
+	ErrNoPoint     = errors.New("no track point in the file")
+	ErrMissingTime = errors.New("track point without time")
+)
+
+// GPX is the root element of a GPX file.
+type GPX struct {
+	XMLName   xml.Name   `xml:"gpx"`
+	Namespace string     `xml:"xmlns,attr,omitempty"`
+	Version   string     `xml:"version,attr"`
+	Creator   string     `xml:"creator,attr"`
+	Metadata  *Metadata  `xml:"metadata"`
+	Waypoints []Waypoint `xml:"wpt"`
+	Tracks    []Track    `xml:"trk"`
+}
+
+type Metadata struct {
+	Name string     `xml:"name,omitempty"`
+	Time *time.Time `xml:"time,omitempty"`
+}
+
+// Waypoint is a point of interest of the file, the messages of the pilots.
+type Waypoint struct {
+	Latitude    float64    `xml:"lat,attr"`
+	Longitude   float64    `xml:"lon,attr"`
+	Elevation   *float64   `xml:"ele,omitempty"`
+	Time        *time.Time `xml:"time,omitempty"`
+	Name        string     `xml:"name,omitempty"`
+	Description string     `xml:"desc,omitempty"`
+	Type        string     `xml:"type,omitempty"`
+}
+
+// Track is a track of the file, split in segments when the recording was interrupted.
+type Track struct {
+	Name     string    `xml:"name,omitempty"`
+	Segments []Segment `xml:"trkseg"`
+}
+
+type Segment struct {
+	Points []TrackPoint `xml:"trkpt"`
+}
+
+type TrackPoint struct {
+	Latitude  float64 `xml:"lat,attr"`
+	Longitude float64 `xml:"lon,attr"`
+	// Elevation in meters.
+	Elevation *float64   `xml:"ele,omitempty"`
+	Time      *time.Time `xml:"time,omitempty"`
+}
+
+// ToPoints converts the points of all the tracks into tracking points.
+//
+// The points without time cannot be placed in the track, ErrMissingTime is returned.
+func (g *GPX) ToPoints() ([]model.Point, error) {
+	points := []model.Point{}
+
+	for _, track := range g.Tracks {
+		for _, segment := range track.Segments {
+			for _, trackPoint := range segment.Points {
+				if trackPoint.Time == nil {
+					return nil, fmt.Errorf("%w: %f,%f", ErrMissingTime, trackPoint.Latitude, trackPoint.Longitude)
+				}
+
+				point := model.Point{
+					DateTime:    trackPoint.Time.UTC(),
+					Latitude:    trackPoint.Latitude,
+					Longitude:   trackPoint.Longitude,
+					MsgType:     model.MsgTypeTrack,
+					ValidGPSFix: true,
+				}
+				if trackPoint.Elevation != nil {
+					point.Altitude = int(math.Round(*trackPoint.Elevation))
+				}
+
+				points = append(points, point)
+			}
+		}
+	}
+
+	if len(points) == 0 {
+		return nil, ErrNoPoint
+	}
+
+	return points, nil
+}
+
+func Parse(content []byte) (GPX, error) {
+	var gpx GPX
+	if err := xml.Unmarshal(content, &gpx); err != nil {
+		return GPX{}, fmt.Errorf("error unmarshalling gpx: %w", err)
+	}
+
+	return gpx, nil
+}
@@ -0,0 +1,59 @@
+package gpx_test
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"fahy.xyz/livetrack/internal/model"
+	"fahy.xyz/livetrack/internal/model/gpx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	content, err := os.ReadFile("testdata/flight.gpx")
+	require.NoError(t, err)
+
+	file, err := gpx.Parse(content)
+	require.NoError(t, err)
+	assert.Equal(t, "Flyskyhy", file.Creator)
+	require.Len(t, file.Tracks, 1)
+	assert.Equal(t, "Moléson", file.Tracks[0].Name)
+	require.Len(t, file.Tracks[0].Segments, 2)
+
+	points, err := file.ToPoints()
+	require.NoError(t, err)
+	require.Len(t, points, 3)
+	assert.Equal(t, time.Date(2023, time.Month(8), 23, 10, 16, 45, 0, time.UTC), points[0].DateTime)
+	assert.InDelta(t, 46.625167, points[0].Latitude, 0.000001)
+	assert.InDelta(t, 7.206167, points[0].Longitude, 0.000001)
+	assert.Equal(t, 1604, points[0].Altitude)
+	assert.Equal(t, 1607, points[1].Altitude)
+	assert.Equal(t, model.MsgTypeTrack, points[0].MsgType)
+
+	// The time is converted to UTC and the elevation is optional.
+	assert.Equal(t, time.Date(2023, time.Month(8), 23, 10, 16, 47, 0, time.UTC), points[2].DateTime)
+	assert.Equal(t, 0, points[2].Altitude)
+}
+
+func TestParse_Errors(t *testing.T) {
+	t.Parallel()
+
+	_, err := gpx.Parse([]byte("<gpx><trk>"))
+	require.Error(t, err)
+
+	file, err := gpx.Parse([]byte(`<gpx><trk><trkseg></trkseg></trk></gpx>`))
+	require.NoError(t, err)
+
+	_, err = file.ToPoints()
+	require.ErrorIs(t, err, gpx.ErrNoPoint)
+
+	file, err = gpx.Parse([]byte(`<gpx><trk><trkseg><trkpt lat="46.6" lon="7.2"></trkpt></trkseg></trk></gpx>`))
+	require.NoError(t, err)
+
+	_, err = file.ToPoints()
+	require.ErrorIs(t, err, gpx.ErrMissingTime)
+}
@@ -0,0 +1,131 @@
+package igc
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"time"
+
+	"fahy.xyz/livetrack/internal/model"
+)
+
+const (
+	// ContentType is the media type of the IGC files, plain text.
+	ContentType = "application/vnd.fai.igc"
+	// manufacturer is the A record of the files written, X is the code of the non-approved recorders.
+	manufacturer = "XLTLTK livetrack"
+	// recorderType is the name of the recorder in the FR type header.
+	recorderType = "livetrack"
+	// lineEnd is the end of the records required by the specification.
+	lineEnd = "\r\n"
+)
+
+// FromTrack builds a flight log from the points stored for the pilot.
+//
+// The headers are filled from the pilot, the date is the one of the first point.
+func FromTrack(pilot model.Pilot, points []model.Point) Flight {
+	flight := Flight{
+		Pilot:        pilot.Name,
+		Site:         pilot.Home,
+		RecorderType: strings.TrimSuffix(recorderType+","+pilot.TrackerType, ","),
+		Fixes:        make([]Fix, 0, len(points)),
+	}
+
+	if len(points) > 0 {
+		year, month, day := points[0].DateTime.UTC().Date()
+		flight.Date = time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	}
+
+	for _, point := range points {
+		flight.Fixes = append(flight.Fixes, Fix{
+			DateTime:  point.DateTime.UTC(),
+			Latitude:  point.Latitude,
+			Longitude: point.Longitude,
+			Valid:     point.ValidGPSFix,
+			// The trackers have no barometer, only the GNSS altitude is known.
+			GNSSAltitude: point.Altitude,
+		})
+	}
+
+	return flight
+}
+
+// Write writes the flight log as an unsigned IGC file, with the headers followed by a B record by fix.
+func (f *Flight) Write(w io.Writer) error {
+	if len(f.Fixes) == 0 {
+		return ErrNoFix
+	}
+
+	writer := bufio.NewWriter(w)
+
+	records := []string{
+		"A" + manufacturer,
+		"HFDTEDATE:" + f.Date.Format("020106") + ",01",
+		"HFPLTPILOTINCHARGE:" + f.Pilot,
+		"HFCM2CREW2:NIL",
+		"HFGTYGLIDERTYPE:",
+		"HFGIDGLIDERID:",
+		"HFDTMGPSDATUM:WGS84",
+		"HFFTYFRTYPE:" + f.RecorderType,
+		"HFPRSPRESSALTSENSOR:NIL",
+	}
+	if f.Site != "" {
+		records = append(records, "HOSITSITE:"+f.Site)
+	}
+
+	for _, record := range records {
+		if _, err := writer.WriteString(record + lineEnd); err != nil {
+			return fmt.Errorf("error writing header: %w", err)
+		}
+	}
+
+	for _, fix := range f.Fixes {
+		if _, err := writer.WriteString(fix.record() + lineEnd); err != nil {
+			return fmt.Errorf("error writing B record: %w", err)
+		}
+	}
+
+	if err := writer.Flush(); err != nil {
+		return fmt.Errorf("error flushing flight log: %w", err)
+	}
+
+	return nil
+}
+
+// record returns the B record of the fix, e.g. B1016454637510N00712370EA0158701604.
+func (f *Fix) record() string {
+	validity := "V"
+	if f.Valid {
+		validity = validFix
+	}
+
+	return fmt.Sprintf(
+		"B%s%s%s%s%05d%05d",
+		f.DateTime.UTC().Format("150405"),
+		formatCoordinate(f.Latitude, 2, "N", "S"),
+		formatCoordinate(f.Longitude, 3, "E", "W"),
+		validity,
+		f.PressureAltitude,
+		f.GNSSAltitude,
+	)
+}
+
+// formatCoordinate converts decimal degrees into degrees and thousandths of minutes followed by the hemisphere.
+func formatCoordinate(coordinate float64, degreesWidth int, positive, negative string) string {
+	hemisphere := positive
+	if coordinate < 0 {
+		hemisphere = negative
+	}
+
+	thousandths := int(math.Round(math.Abs(coordinate) * minutesFactor))
+
+	return fmt.Sprintf(
+		"%0*d%05d%s",
+		degreesWidth,
+		thousandths/minutesFactor,
+		thousandths%minutesFactor,
+		hemisphere,
+	)
+}
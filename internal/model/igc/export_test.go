@@ -0,0 +1,62 @@
+package igc_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"fahy.xyz/livetrack/internal/model"
+	"fahy.xyz/livetrack/internal/model/igc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFlight_Write(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2023, time.Month(8), 23, 10, 16, 45, 0, time.UTC)
+	pilot := model.Pilot{ID: "0Sqp9zyH3ZOfaWhPi4KeUd2GNfqTW43aG", Name: "Moff", Home: "Nevarro", TrackerType: "spot"}
+	points := []model.Point{
+		{DateTime: start, Latitude: 46.6251667, Longitude: 7.2061667, Altitude: 1604, ValidGPSFix: true},
+		{DateTime: start.Add(13 * time.Hour), Latitude: -33.85, Longitude: -70.5083333, Altitude: -12},
+		// Past midnight, the minutes rounded to 60 are carried to the degrees.
+		{DateTime: start.Add(14 * time.Hour), Latitude: 46.9999999, Longitude: 7.0, Altitude: 500, ValidGPSFix: true},
+	}
+
+	flight := igc.FromTrack(pilot, points)
+
+	var buffer bytes.Buffer
+	require.NoError(t, flight.Write(&buffer))
+
+	lines := strings.Split(strings.TrimSuffix(buffer.String(), "\r\n"), "\r\n")
+	assert.Equal(t, "AXLTLTK livetrack", lines[0])
+	assert.Contains(t, lines, "HFDTEDATE:230823,01")
+	assert.Contains(t, lines, "HFPLTPILOTINCHARGE:Moff")
+	assert.Contains(t, lines, "HFFTYFRTYPE:livetrack,spot")
+	assert.Contains(t, lines, "HOSITSITE:Nevarro")
+	assert.Equal(t, []string{
+		"B1016454637510N00712370EA0000001604",
+		"B2316453351000S07030500WV00000-0012",
+		"B0016454700000N00700000EA0000000500",
+	}, lines[len(lines)-3:])
+
+	// The file written is parsed back into the same fixes.
+	parsed, err := igc.Parse(buffer.Bytes())
+	require.NoError(t, err)
+	assert.Equal(t, "Moff", parsed.Pilot)
+	assert.Equal(t, "Nevarro", parsed.Site)
+	assert.Equal(t, "livetrack,spot", parsed.RecorderType)
+	require.Len(t, parsed.Fixes, 3)
+	assert.Equal(t, points[2].DateTime, parsed.Fixes[2].DateTime)
+	assert.InDelta(t, points[0].Latitude, parsed.Fixes[0].Latitude, 0.00001)
+	assert.Equal(t, -12, parsed.Fixes[1].Altitude())
+	assert.False(t, parsed.Fixes[1].Valid)
+}
+
+func TestFlight_WriteNoFix(t *testing.T) {
+	t.Parallel()
+
+	flight := igc.FromTrack(model.Pilot{Name: "Moff"}, nil)
+	require.ErrorIs(t, flight.Write(&bytes.Buffer{}), igc.ErrNoFix)
+}
@@ -0,0 +1,232 @@
+package igc
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"fahy.xyz/livetrack/internal/model"
+)
+
+var (
+	ErrMissingDate = errors.New("missing date header")
+	ErrNoFix       = errors.New("no fix in the flight log")
+)
+
+const (
+	// minutesFactor is the factor of the thousandths of minutes of the coordinates.
+	minutesFactor = 60 * 1000
+	// validFix is the validity of a 3D fix, V is a 2D fix or no GPS data.
+	validFix = "A"
+	// minRollover is the jump back in time of a fix from the previous one to be past midnight, the smaller jumps
+	// are out of order fixes of the recorder.
+	minRollover = 12 * time.Hour
+)
+
+// dateRegexp matches the date header, in the old (HFDTE240823) or new (HFDTEDATE:240823,01) format.
+var dateRegexp = regexp.MustCompile(`^HFDTE(?:DATE:)?(\d{2})(\d{2})(\d{2})`)
+
+// fixRegexp matches a B record, e.g.
+//
+//	B1016454637510N00712370EA0158701604
+var fixRegexp = regexp.MustCompile(
+	`^B(\d{2})(\d{2})(\d{2})(\d{2})(\d{5})([NS])(\d{3})(\d{5})([EW])([AV])([-\d]\d{4})([-\d]\d{4})`,
+)
+
+// Flight is a flight log in the IGC format, as recorded by the varios.
+type Flight struct {
+	Date  time.Time
+	Pilot string
+	// Site is the take-off site of the flight.
+	Site string
+	// RecorderType is the vario or the application recording the flight.
+	RecorderType string
+	Fixes        []Fix
+}
+
+// Fix is a B record of the flight log.
+type Fix struct {
+	DateTime  time.Time
+	Latitude  float64
+	Longitude float64
+	// Valid is false for a 2D fix or without GPS data.
+	Valid bool
+	// PressureAltitude in meters, from the barometer.
+	PressureAltitude int
+	// GNSSAltitude in meters, 0 if not available.
+	GNSSAltitude int
+}
+
+// Altitude returns the GNSS altitude, or the pressure altitude if the GNSS one is not available.
+func (f *Fix) Altitude() int {
+	if f.GNSSAltitude != 0 {
+		return f.GNSSAltitude
+	}
+
+	return f.PressureAltitude
+}
+
+// ToPoints converts the fixes into tracking points.
+func (f *Flight) ToPoints() []model.Point {
+	points := make([]model.Point, 0, len(f.Fixes))
+
+	for _, fix := range f.Fixes {
+		points = append(points, model.Point{
+			DateTime:    fix.DateTime,
+			Latitude:    fix.Latitude,
+			Longitude:   fix.Longitude,
+			Altitude:    fix.Altitude(),
+			MsgType:     model.MsgTypeTrack,
+			ValidGPSFix: fix.Valid,
+		})
+	}
+
+	return points
+}
+
+// Parse parses the records of an IGC file.
+//
+// The B records only contain the time of the day, the date comes from the HFDTE header
+// and a fix more than 12 hours earlier than the previous one is considered after midnight.
+// The other fixes earlier than the previous one are out of order and dropped.
+func Parse(content []byte) (Flight, error) {
+	var (
+		flight Flight
+		// days past midnight of the fixes.
+		days int
+	)
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		switch {
+		case strings.HasPrefix(line, "HFDTE"):
+			date, err := parseDate(line)
+			if err != nil {
+				return Flight{}, err
+			}
+
+			flight.Date = date
+		case strings.HasPrefix(line, "HFPLT"):
+			// The pilot header is HFPLTPILOT:Name or HFPLTPILOTINCHARGE:Name.
+			flight.Pilot = headerValue(line)
+		case strings.HasPrefix(line, "HOSIT"), strings.HasPrefix(line, "HFSIT"):
+			flight.Site = headerValue(line)
+		case strings.HasPrefix(line, "HFFTY"):
+			flight.RecorderType = headerValue(line)
+		case strings.HasPrefix(line, "B"):
+			if flight.Date.IsZero() {
+				return Flight{}, ErrMissingDate
+			}
+
+			fix, err := parseFix(line, flight.Date)
+			if err != nil {
+				return Flight{}, err
+			}
+
+			fix.DateTime = fix.DateTime.AddDate(0, 0, days)
+
+			if len(flight.Fixes) > 0 {
+				previous := flight.Fixes[len(flight.Fixes)-1].DateTime
+
+				switch {
+				case previous.Sub(fix.DateTime) > minRollover:
+					// Past midnight UTC, the time of the day restarts from zero.
+					days++
+					fix.DateTime = fix.DateTime.AddDate(0, 0, 1)
+				case fix.DateTime.Before(previous):
+					continue
+				}
+			}
+
+			flight.Fixes = append(flight.Fixes, fix)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return Flight{}, fmt.Errorf("error reading flight log: %w", err)
+	}
+
+	if len(flight.Fixes) == 0 {
+		return Flight{}, ErrNoFix
+	}
+
+	return flight, nil
+}
+
+// headerValue returns the value after the colon of a header, empty if there is none.
+func headerValue(line string) string {
+	_, value, _ := strings.Cut(line, ":")
+
+	return strings.TrimSpace(value)
+}
+
+func parseDate(line string) (time.Time, error) {
+	matches := dateRegexp.FindStringSubmatch(line)
+	if matches == nil {
+		return time.Time{}, fmt.Errorf("error parsing date header %q", line)
+	}
+
+	date, err := time.Parse("020106", matches[1]+matches[2]+matches[3])
+	if err != nil {
+		return time.Time{}, fmt.Errorf("error parsing date header %q: %w", line, err)
+	}
+
+	return date, nil
+}
+
+func parseFix(line string, date time.Time) (Fix, error) {
+	matches := fixRegexp.FindStringSubmatch(line)
+	if matches == nil {
+		return Fix{}, fmt.Errorf("error parsing B record %q", line)
+	}
+
+	// The groups are only digits, the conversions cannot fail.
+	hour, _ := strconv.Atoi(matches[1])
+	minute, _ := strconv.Atoi(matches[2])
+	second, _ := strconv.Atoi(matches[3])
+
+	latitude := parseCoordinate(matches[4], matches[5], matches[6] == "S")
+	longitude := parseCoordinate(matches[7], matches[8], matches[9] == "W")
+
+	pressureAltitude, err := strconv.Atoi(matches[11])
+	if err != nil {
+		return Fix{}, fmt.Errorf("error parsing pressure altitude of %q: %w", line, err)
+	}
+
+	gnssAltitude, err := strconv.Atoi(matches[12])
+	if err != nil {
+		return Fix{}, fmt.Errorf("error parsing GNSS altitude of %q: %w", line, err)
+	}
+
+	offset := time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute + time.Duration(second)*time.Second
+
+	return Fix{
+		DateTime:         date.Add(offset),
+		Latitude:         latitude,
+		Longitude:        longitude,
+		Valid:            matches[10] == validFix,
+		PressureAltitude: pressureAltitude,
+		GNSSAltitude:     gnssAltitude,
+	}, nil
+}
+
+// parseCoordinate converts degrees and thousandths of minutes into decimal degrees.
+func parseCoordinate(degrees, minutes string, negative bool) float64 {
+	deg, _ := strconv.Atoi(degrees)
+	mins, _ := strconv.Atoi(minutes)
+
+	coordinate := float64(deg) + float64(mins)/minutesFactor
+	if negative {
+		return -coordinate
+	}
+
+	return coordinate
+}
@@ -0,0 +1,107 @@
+package igc_test
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"fahy.xyz/livetrack/internal/model"
+	"fahy.xyz/livetrack/internal/model/igc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	content, err := os.ReadFile("testdata/flight.igc")
+	require.NoError(t, err)
+
+	flight, err := igc.Parse(content)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2023, time.Month(8), 23, 0, 0, 0, 0, time.UTC), flight.Date)
+	assert.Equal(t, "Moff Gideon", flight.Pilot)
+	require.Len(t, flight.Fixes, 5)
+
+	fix := flight.Fixes[0]
+	assert.Equal(t, time.Date(2023, time.Month(8), 23, 10, 16, 45, 0, time.UTC), fix.DateTime)
+	assert.InDelta(t, 46.6251667, fix.Latitude, 0.000001)
+	assert.InDelta(t, 7.2061667, fix.Longitude, 0.000001)
+	assert.True(t, fix.Valid)
+	assert.Equal(t, 1587, fix.PressureAltitude)
+	assert.Equal(t, 1604, fix.GNSSAltitude)
+
+	// Without GNSS altitude, the pressure altitude is used.
+	assert.False(t, flight.Fixes[3].Valid)
+	assert.Equal(t, 1595, flight.Fixes[3].Altitude())
+
+	points := flight.ToPoints()
+	require.Len(t, points, 5)
+	assert.Equal(t, fix.DateTime, points[0].DateTime)
+	assert.Equal(t, 1604, points[0].Altitude)
+	assert.Equal(t, model.MsgTypeTrack, points[0].MsgType)
+	assert.True(t, points[0].ValidGPSFix)
+	assert.False(t, points[3].ValidGPSFix)
+}
+
+func TestParse_SouthWestAfterMidnight(t *testing.T) {
+	t.Parallel()
+
+	flight, err := igc.Parse([]byte(
+		"HFDTE311223\n" +
+			"B2359593351000S07030500WA-001200000\n" +
+			"B0000013351010S07030510WA0001000000\n",
+	))
+	require.NoError(t, err)
+	require.Len(t, flight.Fixes, 2)
+	assert.InDelta(t, -33.85, flight.Fixes[0].Latitude, 0.000001)
+	assert.InDelta(t, -70.508333, flight.Fixes[0].Longitude, 0.000001)
+	assert.Equal(t, -12, flight.Fixes[0].Altitude())
+	assert.Equal(t, time.Date(2024, time.Month(1), 1, 0, 0, 1, 0, time.UTC), flight.Fixes[1].DateTime)
+}
+
+func TestParse_OutOfOrder(t *testing.T) {
+	t.Parallel()
+
+	// A fix one second back in time is a glitch of the recorder, not a rollover past midnight.
+	flight, err := igc.Parse([]byte(
+		"HFDTE230823\n" +
+			"B1016454637510N00712370EA0158701604\n" +
+			"B1016464637520N00712380EA0158801605\n" +
+			"B1016454637530N00712390EA0158901606\n" +
+			"B1016474637540N00712400EA0159001607\n",
+	))
+	require.NoError(t, err)
+	require.Len(t, flight.Fixes, 3)
+	assert.Equal(t, time.Date(2023, time.Month(8), 23, 10, 16, 46, 0, time.UTC), flight.Fixes[1].DateTime)
+	assert.Equal(t, time.Date(2023, time.Month(8), 23, 10, 16, 47, 0, time.UTC), flight.Fixes[2].DateTime)
+	assert.Equal(t, 1607, flight.Fixes[2].Altitude())
+}
+
+func TestParse_Errors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		content string
+		err     error
+	}{
+		{name: "missing date", content: "B1016454637510N00712370EA0158701604\n", err: igc.ErrMissingDate},
+		{name: "no fix", content: "HFDTE230823\nLXCTFLIGHT END\n", err: igc.ErrNoFix},
+		{name: "invalid date", content: "HFDTE321323\n"},
+		{name: "invalid fix", content: "HFDTE230823\nB1016454637510N\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := igc.Parse([]byte(tt.content))
+			require.Error(t, err)
+
+			if tt.err != nil {
+				require.ErrorIs(t, err, tt.err)
+			}
+		})
+	}
+}
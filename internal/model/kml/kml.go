@@ -0,0 +1,235 @@
+// Package kml writes the tracks in the KML and KMZ formats of Google Earth.
+package kml
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"fahy.xyz/livetrack/internal/model"
+)
+
+const (
+	Namespace = "http://www.opengis.net/kml/2.2"
+	// ContentType is the media type of the KML files.
+	ContentType = "application/vnd.google-earth.kml+xml"
+	// ContentTypeKMZ is the media type of the KMZ files, a zip archive with the KML file.
+	ContentTypeKMZ = "application/vnd.google-earth.kmz"
+	// kmzDocument is the name of the KML file in the KMZ archive, read by Google Earth.
+	kmzDocument = "doc.kml"
+	// altitudeMode places the points at their altitude above sea level.
+	altitudeMode = "absolute"
+	lineWidth    = 3
+	// polygonOpacity is the alpha of the extrusion below the tracks, the line is opaque.
+	polygonOpacity   = "7f"
+	messageStyleID   = "message"
+	emergencyStyleID = "emergency"
+	messageIcon      = "https://maps.google.com/mapfiles/kml/paddle/grn-circle.png"
+	emergencyIcon    = "https://maps.google.com/mapfiles/kml/paddle/red-stars.png"
+)
+
+// KML is the root element of a KML file.
+type KML struct {
+	XMLName   xml.Name `xml:"kml"`
+	Namespace string   `xml:"xmlns,attr"`
+	Document  Document `xml:"Document"`
+}
+
+type Document struct {
+	Name    string   `xml:"name"`
+	Styles  []Style  `xml:"Style"`
+	Folders []Folder `xml:"Folder"`
+}
+
+type Style struct {
+	ID        string     `xml:"id,attr"`
+	IconStyle *IconStyle `xml:"IconStyle,omitempty"`
+	LineStyle *LineStyle `xml:"LineStyle,omitempty"`
+	PolyStyle *PolyStyle `xml:"PolyStyle,omitempty"`
+}
+
+type IconStyle struct {
+	Href string `xml:"Icon>href"`
+}
+
+// LineStyle is the style of the track, the color is in the aabbggrr format.
+type LineStyle struct {
+	Color string `xml:"color"`
+	Width int    `xml:"width"`
+}
+
+// PolyStyle is the style of the extrusion of the track to the ground.
+type PolyStyle struct {
+	Color string `xml:"color"`
+}
+
+// Folder groups the track and the messages of a pilot.
+type Folder struct {
+	Name       string      `xml:"name"`
+	Placemarks []Placemark `xml:"Placemark"`
+}
+
+type Placemark struct {
+	Name        string      `xml:"name"`
+	Description string      `xml:"description,omitempty"`
+	When        *time.Time  `xml:"TimeStamp>when,omitempty"`
+	StyleURL    string      `xml:"styleUrl"`
+	LineString  *LineString `xml:"LineString,omitempty"`
+	Point       *Point      `xml:"Point,omitempty"`
+}
+
+type LineString struct {
+	Extrude      int    `xml:"extrude"`
+	Tessellate   int    `xml:"tessellate"`
+	AltitudeMode string `xml:"altitudeMode"`
+	Coordinates  string `xml:"coordinates"`
+}
+
+type Point struct {
+	AltitudeMode string `xml:"altitudeMode"`
+	Coordinates  string `xml:"coordinates"`
+}
+
+// palette returns the colors of the tracks, in the bbggrr format of KML.
+func palette() []string {
+	return []string{"0000ff", "ff0000", "00a5ff", "800080", "00ff00", "ffff00", "ff00ff", "008080", "ff8000", "000080"}
+}
+
+// FromTracks builds a KML file with a folder by pilot, containing its coloured track and its messages.
+//
+// The key of the map is the name of the pilot, the folders are sorted by name and the pilots without point are skipped.
+func FromTracks(name string, tracks map[string][]model.Point) KML {
+	document := Document{
+		Name: name,
+		Styles: []Style{
+			{ID: messageStyleID, IconStyle: &IconStyle{Href: messageIcon}},
+			{ID: emergencyStyleID, IconStyle: &IconStyle{Href: emergencyIcon}},
+		},
+	}
+
+	pilots := make([]string, 0, len(tracks))
+	for pilot := range tracks {
+		pilots = append(pilots, pilot)
+	}
+
+	slices.Sort(pilots)
+
+	colors := palette()
+
+	for _, pilot := range pilots {
+		points := tracks[pilot]
+		if len(points) == 0 {
+			continue
+		}
+
+		// The colors are reused when there are more pilots than colors.
+		styleID := fmt.Sprintf("track-%d", len(document.Folders))
+		color := colors[len(document.Folders)%len(colors)]
+		document.Styles = append(document.Styles, Style{
+			ID:        styleID,
+			LineStyle: &LineStyle{Color: "ff" + color, Width: lineWidth},
+			PolyStyle: &PolyStyle{Color: polygonOpacity + color},
+		})
+
+		coordinates := make([]string, 0, len(points))
+		for _, point := range points {
+			coordinates = append(coordinates, formatCoordinates(point))
+		}
+
+		folder := Folder{
+			Name: pilot,
+			Placemarks: []Placemark{{
+				Name:     pilot,
+				StyleURL: "#" + styleID,
+				LineString: &LineString{
+					Extrude:      1,
+					Tessellate:   1,
+					AltitudeMode: altitudeMode,
+					Coordinates:  strings.Join(coordinates, " "),
+				},
+			}},
+		}
+
+		for _, point := range points {
+			if point.IsMessage() {
+				folder.Placemarks = append(folder.Placemarks, messagePlacemark(pilot, point))
+			}
+		}
+
+		document.Folders = append(document.Folders, folder)
+	}
+
+	return KML{Namespace: Namespace, Document: document}
+}
+
+func messagePlacemark(pilot string, point model.Point) Placemark {
+	msgType := point.MsgType
+	styleID := messageStyleID
+
+	// An SOS of a garmin tracker is as critical as a SPOT HELP message.
+	if point.IsEmergency() {
+		msgType = model.MsgTypeHelp
+		styleID = emergencyStyleID
+	}
+
+	dateTime := point.DateTime.UTC()
+
+	return Placemark{
+		Name:        fmt.Sprintf("%s %s", pilot, msgType),
+		Description: point.MsgContent,
+		When:        &dateTime,
+		StyleURL:    "#" + styleID,
+		Point: &Point{
+			AltitudeMode: altitudeMode,
+			Coordinates:  formatCoordinates(point),
+		},
+	}
+}
+
+// formatCoordinates returns the coordinates of the point in the lon,lat,alt format of KML.
+func formatCoordinates(point model.Point) string {
+	return strconv.FormatFloat(point.Longitude, 'f', -1, 64) + "," +
+		strconv.FormatFloat(point.Latitude, 'f', -1, 64) + "," +
+		strconv.Itoa(point.Altitude)
+}
+
+// Write writes the KML file with its XML header.
+func (k *KML) Write(w io.Writer) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return fmt.Errorf("error writing header: %w", err)
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+
+	if err := encoder.Encode(k); err != nil {
+		return fmt.Errorf("error encoding kml: %w", err)
+	}
+
+	return nil
+}
+
+// WriteKMZ writes the KML file compressed in a KMZ archive.
+func (k *KML) WriteKMZ(w io.Writer) error {
+	archive := zip.NewWriter(w)
+
+	document, err := archive.Create(kmzDocument)
+	if err != nil {
+		return fmt.Errorf("error creating kml in archive: %w", err)
+	}
+
+	if err = k.Write(document); err != nil {
+		return err
+	}
+
+	if err = archive.Close(); err != nil {
+		return fmt.Errorf("error closing archive: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,97 @@
+package kml_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"testing"
+	"time"
+
+	"fahy.xyz/livetrack/internal/model"
+	"fahy.xyz/livetrack/internal/model/kml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func tracks() map[string][]model.Point {
+	start := time.Date(2023, time.Month(8), 22, 8, 0, 0, 0, time.UTC)
+
+	return map[string][]model.Point{
+		"Moff": {
+			{DateTime: start, Latitude: 46.45669, Longitude: 6.88411, Altitude: 479, MsgType: "UNLIMITED-TRACK"},
+			{
+				DateTime:   start.Add(5 * time.Minute),
+				Latitude:   46.45549,
+				Longitude:  6.8854,
+				Altitude:   512,
+				MsgType:    model.MsgTypeOK,
+				MsgContent: "Pilot has landed safely",
+			},
+		},
+		"Bix": {
+			{DateTime: start, Latitude: 46.6, Longitude: 7.2, Altitude: 1600, MsgType: model.MsgTypeTrack},
+			{DateTime: start.Add(time.Minute), Latitude: 46.61, Longitude: 7.21, Altitude: 1650, MsgType: "SOS declared from device.", Emergency: true},
+		},
+		"Cassian": {},
+	}
+}
+
+func TestFromTracks(t *testing.T) {
+	t.Parallel()
+
+	file := kml.FromTracks("2023-08-22", tracks())
+	assert.Equal(t, "2023-08-22", file.Document.Name)
+	require.Len(t, file.Document.Folders, 2)
+
+	bix := file.Document.Folders[0]
+	assert.Equal(t, "Bix", bix.Name)
+	require.Len(t, bix.Placemarks, 2)
+	assert.Equal(t, "#track-0", bix.Placemarks[0].StyleURL)
+	assert.Equal(t, "7.2,46.6,1600 7.21,46.61,1650", bix.Placemarks[0].LineString.Coordinates)
+	assert.Equal(t, 1, bix.Placemarks[0].LineString.Extrude)
+	assert.Equal(t, "Bix HELP", bix.Placemarks[1].Name)
+	assert.Equal(t, "#emergency", bix.Placemarks[1].StyleURL)
+
+	moff := file.Document.Folders[1]
+	assert.Equal(t, "#track-1", moff.Placemarks[0].StyleURL)
+	require.Len(t, moff.Placemarks, 2)
+	assert.Equal(t, "Moff OK", moff.Placemarks[1].Name)
+	assert.Equal(t, "Pilot has landed safely", moff.Placemarks[1].Description)
+	assert.Equal(t, "6.8854,46.45549,512", moff.Placemarks[1].Point.Coordinates)
+
+	// Each track has its own color.
+	require.Len(t, file.Document.Styles, 4)
+	assert.NotEqual(t, file.Document.Styles[2].LineStyle.Color, file.Document.Styles[3].LineStyle.Color)
+}
+
+func TestKML_Write(t *testing.T) {
+	t.Parallel()
+
+	file := kml.FromTracks("2023-08-22", tracks())
+
+	var buffer bytes.Buffer
+	require.NoError(t, file.Write(&buffer))
+	assert.Contains(t, buffer.String(), `<kml xmlns="http://www.opengis.net/kml/2.2">`)
+	assert.Contains(t, buffer.String(), `<altitudeMode>absolute</altitudeMode>`)
+	assert.Contains(t, buffer.String(), `<when>2023-08-22T08:05:00Z</when>`)
+
+	var kmz bytes.Buffer
+	require.NoError(t, file.WriteKMZ(&kmz))
+
+	archive, err := zip.NewReader(bytes.NewReader(kmz.Bytes()), int64(kmz.Len()))
+	require.NoError(t, err)
+	require.Len(t, archive.File, 1)
+	assert.Equal(t, "doc.kml", archive.File[0].Name)
+
+	document, err := archive.File[0].Open()
+	require.NoError(t, err)
+
+	content, err := io.ReadAll(document)
+	require.NoError(t, err)
+	assert.Equal(t, buffer.String(), string(content))
+
+	var parsed kml.KML
+	require.NoError(t, xml.Unmarshal(content, &parsed))
+	assert.Len(t, parsed.Document.Folders, 2)
+}
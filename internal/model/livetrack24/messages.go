@@ -0,0 +1,70 @@
+// Package livetrack24 parses the live tracks of the Livetrack24 feed.
+//
+// The format of the response follows the live tracking API described in the Livetrack24 documentation wiki. The
+// fixtures of the tests are synthetic, written from this description without a recorded response: the names of
+// the fields and the units of the speed and course are still to be checked against a real payload, which can be
+// taken from the archive of the raw payloads once the fetcher runs against the feed.
+package livetrack24
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"fahy.xyz/livetrack/internal/model"
+)
+
+var ErrInvalidTime = errors.New("invalid time")
+
+// Response is the live track of a user, as streamed by XCTrack or Flyskyhy.
+type Response struct {
+	Error    string  `json:"error"`
+	Username string  `json:"username"`
+	TrackID  int64   `json:"trackID"`
+	Points   []Point `json:"points"`
+}
+
+// Point is a position of the live track.
+type Point struct {
+	Latitude  float64 `json:"lat"`
+	Longitude float64 `json:"lon"`
+	Altitude  int     `json:"alt"`
+	// Speed over ground in km/h.
+	SOG float64 `json:"sog"`
+	// Course over ground in degrees.
+	COG      float64 `json:"cog"`
+	UnixTime int64   `json:"tm"`
+}
+
+func (r *Response) ToPoints() ([]model.Point, error) {
+	points := []model.Point{}
+
+	for _, point := range r.Points {
+		if point.UnixTime <= 0 {
+			return nil, fmt.Errorf("error parsing time %d: %w", point.UnixTime, ErrInvalidTime)
+		}
+
+		points = append(points, model.Point{
+			DateTime:    time.Unix(point.UnixTime, 0).UTC(),
+			Latitude:    point.Latitude,
+			Longitude:   point.Longitude,
+			Altitude:    point.Altitude,
+			MsgType:     model.MsgTypeTrack,
+			ValidGPSFix: true,
+			Velocity:    point.SOG,
+			Course:      point.COG,
+		})
+	}
+
+	return points, nil
+}
+
+func Parse(content []byte) (Response, error) {
+	var response Response
+	if err := json.Unmarshal(content, &response); err != nil {
+		return Response{}, fmt.Errorf("error unmarshalling content: %w", err)
+	}
+
+	return response, nil
+}
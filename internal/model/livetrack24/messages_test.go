@@ -0,0 +1,60 @@
+package livetrack24_test
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"fahy.xyz/livetrack/internal/model"
+	"fahy.xyz/livetrack/internal/model/livetrack24"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMessagesToPoint(t *testing.T) {
+	t.Parallel()
+
+	content, err := os.ReadFile("testdata/response.json")
+	require.NoError(t, err)
+
+	response, err := livetrack24.Parse(content)
+	require.NoError(t, err)
+
+	points, err := response.ToPoints()
+	require.NoError(t, err)
+	assert.Len(t, points, 3)
+	assert.Equal(t, model.MsgTypeTrack, points[0].MsgType)
+	assert.Equal(t, time.Date(2023, time.Month(8), 23, 10, 16, 45, 0, time.UTC), points[0].DateTime)
+	assert.InEpsilon(t, 46.62861, points[1].Latitude, 0.1)
+	assert.InEpsilon(t, 7.21215, points[1].Longitude, 0.1)
+	assert.Equal(t, 1754, points[1].Altitude)
+	assert.InDelta(t, 32.4, points[1].Velocity, 0.01)
+	assert.InDelta(t, 45.0, points[1].Course, 0.01)
+}
+
+func TestEmptyMessagesToPoint(t *testing.T) {
+	t.Parallel()
+
+	content, err := os.ReadFile("testdata/response_empty.json")
+	require.NoError(t, err)
+
+	response, err := livetrack24.Parse(content)
+	require.NoError(t, err)
+
+	points, err := response.ToPoints()
+	require.NoError(t, err)
+	assert.Empty(t, points)
+}
+
+func TestMessagesParseError(t *testing.T) {
+	t.Parallel()
+
+	content, err := os.ReadFile("testdata/response_not_found.json")
+	require.NoError(t, err)
+
+	response, err := livetrack24.Parse(content)
+	require.NoError(t, err)
+
+	assert.Equal(t, "user not found", response.Error)
+	assert.Empty(t, response.Points)
+}
@@ -0,0 +1,97 @@
+package meshtastic
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"fahy.xyz/livetrack/internal/model"
+)
+
+var (
+	ErrNotPosition = errors.New("not a position packet")
+	ErrNoPosition  = errors.New("position packet without coordinates")
+)
+
+const (
+	// TypePosition is the type of the packets containing a position.
+	TypePosition = "position"
+	// coordinateFactor is the factor of the integer coordinates.
+	coordinateFactor = 1e7
+	// trackFactor is the factor of the integer ground track.
+	trackFactor = 1e5
+	msToKmh     = 3.6
+)
+
+// Packet is a packet of a Meshtastic node, as published by the JSON output of the MQTT module.
+type Packet struct {
+	ID        uint32          `json:"id"`
+	From      uint32          `json:"from"`
+	Sender    string          `json:"sender"`
+	Type      string          `json:"type"`
+	Timestamp int64           `json:"timestamp"`
+	Payload   json.RawMessage `json:"payload"`
+}
+
+// Position is the payload of a position packet.
+type Position struct {
+	LatitudeI  int32 `json:"latitude_i"`
+	LongitudeI int32 `json:"longitude_i"`
+	Altitude   int   `json:"altitude"`
+	// GroundSpeed in m/s.
+	GroundSpeed float64 `json:"ground_speed"`
+	// GroundTrack in 1e-5 degrees.
+	GroundTrack float64 `json:"ground_track"`
+	// Time of the fix, in seconds since epoch.
+	Time int64 `json:"time"`
+}
+
+// NodeID returns the ID of the node sending the packet, e.g. !7efeee00.
+func (p *Packet) NodeID() string {
+	return fmt.Sprintf("!%08x", p.From)
+}
+
+// ToPoint converts a position packet into a tracking point.
+//
+// ErrNotPosition is returned for the other types of packets.
+func (p *Packet) ToPoint() (model.Point, error) {
+	if p.Type != TypePosition {
+		return model.Point{}, ErrNotPosition
+	}
+
+	var position Position
+	if err := json.Unmarshal(p.Payload, &position); err != nil {
+		return model.Point{}, fmt.Errorf("error unmarshalling position: %w", err)
+	}
+
+	if position.LatitudeI == 0 && position.LongitudeI == 0 {
+		return model.Point{}, ErrNoPosition
+	}
+
+	// The time of the fix is missing if the node has no clock, use the time of the packet.
+	unixTime := position.Time
+	if unixTime == 0 {
+		unixTime = p.Timestamp
+	}
+
+	return model.Point{
+		DateTime:    time.Unix(unixTime, 0).UTC(),
+		Latitude:    float64(position.LatitudeI) / coordinateFactor,
+		Longitude:   float64(position.LongitudeI) / coordinateFactor,
+		Altitude:    position.Altitude,
+		MsgType:     model.MsgTypeTrack,
+		ValidGPSFix: true,
+		Velocity:    position.GroundSpeed * msToKmh,
+		Course:      position.GroundTrack / trackFactor,
+	}, nil
+}
+
+func Parse(content []byte) (Packet, error) {
+	var packet Packet
+	if err := json.Unmarshal(content, &packet); err != nil {
+		return Packet{}, fmt.Errorf("error unmarshalling packet: %w", err)
+	}
+
+	return packet, nil
+}
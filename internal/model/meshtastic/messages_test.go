@@ -0,0 +1,57 @@
+package meshtastic_test
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"fahy.xyz/livetrack/internal/model"
+	"fahy.xyz/livetrack/internal/model/meshtastic"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPacketToPoint(t *testing.T) {
+	t.Parallel()
+
+	content, err := os.ReadFile("testdata/position.json")
+	require.NoError(t, err)
+
+	packet, err := meshtastic.Parse(content)
+	require.NoError(t, err)
+	assert.Equal(t, "!7efeee00", packet.NodeID())
+
+	point, err := packet.ToPoint()
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2023, time.Month(8), 23, 10, 16, 45, 0, time.UTC), point.DateTime)
+	assert.InDelta(t, 46.62515, point.Latitude, 0.0000001)
+	assert.InDelta(t, 7.20613, point.Longitude, 0.0000001)
+	assert.Equal(t, 1603, point.Altitude)
+	assert.InDelta(t, 18.0, point.Velocity, 0.001)
+	assert.InDelta(t, 112.5, point.Course, 0.001)
+	assert.Equal(t, model.MsgTypeTrack, point.MsgType)
+}
+
+func TestPacketToPoint_NotPosition(t *testing.T) {
+	t.Parallel()
+
+	content, err := os.ReadFile("testdata/telemetry.json")
+	require.NoError(t, err)
+
+	packet, err := meshtastic.Parse(content)
+	require.NoError(t, err)
+
+	_, err = packet.ToPoint()
+	require.ErrorIs(t, err, meshtastic.ErrNotPosition)
+}
+
+func TestPacketToPoint_NoCoordinates(t *testing.T) {
+	t.Parallel()
+
+	packet, err := meshtastic.Parse([]byte(`{"from":1,"type":"position","timestamp":1692785806,"payload":{"altitude":1603}}`))
+	require.NoError(t, err)
+	assert.Equal(t, "!00000001", packet.NodeID())
+
+	_, err = packet.ToPoint()
+	require.ErrorIs(t, err, meshtastic.ErrNoPosition)
+}
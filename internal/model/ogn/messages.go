@@ -0,0 +1,181 @@
+package ogn
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"time"
+
+	"fahy.xyz/livetrack/internal/model"
+)
+
+var ErrNotPosition = errors.New("not a position report")
+
+const (
+	feetToMeters = 0.3048
+	knotsToKmh   = 1.852
+	// maxClockSkew is the delay in the future accepted for a report before considering it from the day before.
+	maxClockSkew = time.Hour
+	// stealthFlag and noTrackingFlag are the bits of the first byte of the id field set by the pilot in the
+	// configuration of the device.
+	stealthFlag    = 0x80
+	noTrackingFlag = 0x40
+)
+
+// positionRegexp matches an APRS position report with timestamp, as sent by the OGN, e.g.
+//
+//	FLRDDE626>APRS,qAS,EGHL:/074548h5111.32N/00102.04W'086/007/A=000607 !W52! id0ADDE626 -019fpm
+var positionRegexp = regexp.MustCompile(
+	`^([A-Z0-9]+)>[^:]+:[/@](\d{2})(\d{2})(\d{2})h` +
+		`(\d{2})(\d{2}\.\d{2})([NS]).(\d{3})(\d{2}\.\d{2})([EW]).` +
+		`(?:(\d{3})/(\d{3}))?(?:/A=(-?\d{6}))?(.*)$`,
+)
+
+// precisionRegexp matches the additional digits of the minutes of latitude and longitude.
+var precisionRegexp = regexp.MustCompile(`!W(\d)(\d)!`)
+
+// idRegexp matches the id field, the first byte holds the flags and the address type, followed by the address.
+var idRegexp = regexp.MustCompile(`\bid([0-9A-Fa-f]{2})[0-9A-Fa-f]{6}\b`)
+
+// Position is a position report of a FLARM, FANET or OGN device.
+type Position struct {
+	// Callsign is the device ID prefixed by its type, e.g. FLRDDE626.
+	Callsign  string
+	DateTime  time.Time
+	Latitude  float64
+	Longitude float64
+	// Altitude in meters.
+	Altitude int
+	// Course in degrees.
+	Course float64
+	// Speed in km/h.
+	Speed float64
+	// Stealth and NoTracking are the privacy flags of the device, the positions must not be shown nor stored.
+	Stealth    bool
+	NoTracking bool
+}
+
+// Parse parses an APRS line received at the given time.
+//
+// The reports only contain the time of the day, the date is the one of the reception.
+// ErrNotPosition is returned for the lines that are not position reports.
+func Parse(line string, received time.Time) (Position, error) {
+	matches := positionRegexp.FindStringSubmatch(line)
+	if matches == nil {
+		return Position{}, ErrNotPosition
+	}
+
+	dateTime, err := parseTime(matches[2], matches[3], matches[4], received)
+	if err != nil {
+		return Position{}, fmt.Errorf("error parsing time: %w", err)
+	}
+
+	comment := matches[14]
+
+	var latExtra, lonExtra float64
+	if precision := precisionRegexp.FindStringSubmatch(comment); precision != nil {
+		latExtra, _ = strconv.ParseFloat(precision[1], 64)
+		lonExtra, _ = strconv.ParseFloat(precision[2], 64)
+	}
+
+	latitude, err := parseCoordinate(matches[5], matches[6], latExtra, matches[7] == "S")
+	if err != nil {
+		return Position{}, fmt.Errorf("error parsing latitude: %w", err)
+	}
+
+	longitude, err := parseCoordinate(matches[8], matches[9], lonExtra, matches[10] == "W")
+	if err != nil {
+		return Position{}, fmt.Errorf("error parsing longitude: %w", err)
+	}
+
+	position := Position{
+		Callsign:  matches[1],
+		DateTime:  dateTime,
+		Latitude:  latitude,
+		Longitude: longitude,
+	}
+
+	if matches[11] != "" {
+		course, _ := strconv.ParseFloat(matches[11], 64)
+		speed, _ := strconv.ParseFloat(matches[12], 64)
+		position.Course = course
+		position.Speed = speed * knotsToKmh
+	}
+
+	if matches[13] != "" {
+		altitude, _ := strconv.ParseFloat(matches[13], 64)
+		position.Altitude = int(math.Round(altitude * feetToMeters))
+	}
+
+	if id := idRegexp.FindStringSubmatch(comment); id != nil {
+		flags, _ := strconv.ParseUint(id[1], 16, 8)
+		position.Stealth = flags&stealthFlag != 0
+		position.NoTracking = flags&noTrackingFlag != 0
+	}
+
+	return position, nil
+}
+
+// AllowsTracking returns false if the device has the stealth or no-tracking flag, as required by the OGN data policy.
+func (p *Position) AllowsTracking() bool {
+	return !p.Stealth && !p.NoTracking
+}
+
+// ToPoint converts the position into a tracking point.
+func (p *Position) ToPoint() model.Point {
+	return model.Point{
+		DateTime:    p.DateTime,
+		Latitude:    p.Latitude,
+		Longitude:   p.Longitude,
+		Altitude:    p.Altitude,
+		MsgType:     model.MsgTypeTrack,
+		ValidGPSFix: true,
+		Velocity:    p.Speed,
+		Course:      p.Course,
+	}
+}
+
+// parseTime returns the time of the report, on the day it was received.
+func parseTime(hours, minutes, seconds string, received time.Time) (time.Time, error) {
+	layout := "150405"
+
+	timeOfDay, err := time.Parse(layout, hours+minutes+seconds)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("parsing %s%s%s: %w", hours, minutes, seconds, err)
+	}
+
+	received = received.UTC()
+	dateTime := time.Date(
+		received.Year(), received.Month(), received.Day(),
+		timeOfDay.Hour(), timeOfDay.Minute(), timeOfDay.Second(), 0, time.UTC,
+	)
+
+	// Received just after midnight, the report is from the day before.
+	if dateTime.After(received.Add(maxClockSkew)) {
+		dateTime = dateTime.AddDate(0, 0, -1)
+	}
+
+	return dateTime, nil
+}
+
+// parseCoordinate converts degrees and decimal minutes into decimal degrees.
+func parseCoordinate(degrees, minutes string, extraDigit float64, negative bool) (float64, error) {
+	deg, err := strconv.ParseFloat(degrees, 64)
+	if err != nil {
+		return 0, fmt.Errorf("parsing degrees %s: %w", degrees, err)
+	}
+
+	mins, err := strconv.ParseFloat(minutes, 64)
+	if err != nil {
+		return 0, fmt.Errorf("parsing minutes %s: %w", minutes, err)
+	}
+
+	coordinate := deg + (mins+extraDigit/1000)/60
+	if negative {
+		coordinate = -coordinate
+	}
+
+	return coordinate, nil
+}
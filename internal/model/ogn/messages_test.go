@@ -0,0 +1,123 @@
+package ogn_test
+
+import (
+	"bufio"
+	"os"
+	"testing"
+	"time"
+
+	"fahy.xyz/livetrack/internal/model"
+	"fahy.xyz/livetrack/internal/model/ogn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	received := time.Date(2023, time.Month(8), 23, 10, 17, 0, 0, time.UTC)
+
+	position, err := ogn.Parse(
+		"FNT11F3A4>OGNFNT,qAS,Hochfeld:/101645h4637.51N/00712.37E'045/018/A=005262 !W80! id1E11F3A4 +198fpm gps4x6",
+		received,
+	)
+	require.NoError(t, err)
+	assert.Equal(t, "FNT11F3A4", position.Callsign)
+	assert.Equal(t, time.Date(2023, time.Month(8), 23, 10, 16, 45, 0, time.UTC), position.DateTime)
+	assert.InDelta(t, 46.6253, position.Latitude, 0.000001)
+	assert.InDelta(t, 7.206167, position.Longitude, 0.000001)
+	assert.Equal(t, 1604, position.Altitude)
+	assert.InDelta(t, 45.0, position.Course, 0.01)
+	assert.InDelta(t, 33.336, position.Speed, 0.001)
+
+	point := position.ToPoint()
+	assert.Equal(t, model.MsgTypeTrack, point.MsgType)
+	assert.Equal(t, position.DateTime, point.DateTime)
+	assert.InDelta(t, 33.336, point.Velocity, 0.001)
+}
+
+func TestParse_SouthWest(t *testing.T) {
+	t.Parallel()
+
+	position, err := ogn.Parse(
+		"FLRDDE626>APRS,qAS,EGHL:/074548h5111.32S/00102.04W'086/007/A=000607 id0ADDE626 -019fpm",
+		time.Date(2023, time.Month(8), 23, 8, 0, 0, 0, time.UTC),
+	)
+	require.NoError(t, err)
+	assert.InDelta(t, -51.188667, position.Latitude, 0.000001)
+	assert.InDelta(t, -1.034, position.Longitude, 0.000001)
+	assert.Equal(t, 185, position.Altitude)
+}
+
+func TestParse_Midnight(t *testing.T) {
+	t.Parallel()
+
+	// A report sent just before midnight and received just after is from the day before.
+	position, err := ogn.Parse(
+		"FLRDDE626>APRS,qAS,EGHL:/235958h5111.32N/00102.04W'086/007/A=000607",
+		time.Date(2023, time.Month(8), 24, 0, 0, 5, 0, time.UTC),
+	)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2023, time.Month(8), 23, 23, 59, 58, 0, time.UTC), position.DateTime)
+}
+
+func TestParse_Flags(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		id         string
+		stealth    bool
+		noTracking bool
+	}{
+		{name: "no flag", id: "id0ADDE626"},
+		{name: "no tracking", id: "id4ADDE626", noTracking: true},
+		{name: "stealth", id: "id8ADDE626", stealth: true},
+		{name: "both", id: "idcaDDE626", stealth: true, noTracking: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			position, err := ogn.Parse(
+				"FLRDDE626>APRS,qAS,EGHL:/074548h5111.32N/00102.04W'086/007/A=000607 !W52! "+tt.id+" -019fpm",
+				time.Date(2023, time.Month(8), 23, 8, 0, 0, 0, time.UTC),
+			)
+			require.NoError(t, err)
+			assert.Equal(t, tt.stealth, position.Stealth)
+			assert.Equal(t, tt.noTracking, position.NoTracking)
+			assert.Equal(t, !tt.stealth && !tt.noTracking, position.AllowsTracking())
+		})
+	}
+}
+
+func TestParse_Stream(t *testing.T) {
+	t.Parallel()
+
+	file, err := os.Open("testdata/stream.txt")
+	require.NoError(t, err)
+
+	defer file.Close()
+
+	positions := []ogn.Position{}
+	scanner := bufio.NewScanner(file)
+
+	for scanner.Scan() {
+		position, err := ogn.Parse(scanner.Text(), time.Date(2023, time.Month(8), 23, 10, 17, 0, 0, time.UTC))
+		if err != nil {
+			require.ErrorIs(t, err, ogn.ErrNotPosition)
+
+			continue
+		}
+
+		positions = append(positions, position)
+	}
+
+	require.NoError(t, scanner.Err())
+	// The comments and status lines are skipped, the receivers positions are kept.
+	require.Len(t, positions, 3)
+	assert.Equal(t, "FLRDDE626", positions[0].Callsign)
+	assert.Equal(t, "FNT11F3A4", positions[1].Callsign)
+	assert.Equal(t, "EGHL", positions[2].Callsign)
+}
@@ -0,0 +1,145 @@
+package osmand
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"fahy.xyz/livetrack/internal/model"
+)
+
+var (
+	ErrMissingParameter = errors.New("missing parameter")
+	ErrInvalidParameter = errors.New("invalid parameter")
+)
+
+const (
+	knotsToKmh = 1.852
+	// lowBattery is the battery level in percent under which the battery is considered low.
+	lowBattery = 15
+	// millisecondsThreshold is the timestamp above which the timestamp is in milliseconds.
+	millisecondsThreshold = 100_000_000_000
+)
+
+// Message is a position pushed with the OsmAnd protocol, by OsmAnd, Traccar Client or GPSLogger.
+type Message struct {
+	// Token identifies the pilot, it is sent as the ID of the device.
+	Token string
+	Point model.Point
+}
+
+// Parse parses the parameters of an OsmAnd request.
+//
+// The timestamp is accepted in seconds, milliseconds or RFC 3339, and the speed is in knots
+// like the Traccar Client.
+func Parse(values url.Values) (Message, error) {
+	token := values.Get("id")
+	if token == "" {
+		token = values.Get("deviceid")
+	}
+
+	if token == "" {
+		return Message{}, fmt.Errorf("%w: id", ErrMissingParameter)
+	}
+
+	latitude, err := parseFloat(values, "lat", true)
+	if err != nil {
+		return Message{}, err
+	}
+
+	longitude, err := parseFloat(values, "lon", true)
+	if err != nil {
+		return Message{}, err
+	}
+
+	if latitude < -90 || latitude > 90 || longitude < -180 || longitude > 180 {
+		return Message{}, fmt.Errorf("%w: position %f,%f out of range", ErrInvalidParameter, latitude, longitude)
+	}
+
+	dateTime, err := parseTimestamp(values.Get("timestamp"))
+	if err != nil {
+		return Message{}, err
+	}
+
+	altitude, err := parseFloat(values, "altitude", false)
+	if err != nil {
+		return Message{}, err
+	}
+
+	speed, err := parseFloat(values, "speed", false)
+	if err != nil {
+		return Message{}, err
+	}
+
+	bearing, err := parseFloat(values, "bearing", false)
+	if err != nil {
+		return Message{}, err
+	}
+
+	point := model.Point{
+		DateTime:    dateTime,
+		Latitude:    latitude,
+		Longitude:   longitude,
+		Altitude:    int(altitude),
+		MsgType:     model.MsgTypeTrack,
+		ValidGPSFix: true,
+		Velocity:    speed * knotsToKmh,
+		Course:      bearing,
+	}
+
+	if values.Has("batt") {
+		battery, err := parseFloat(values, "batt", false)
+		if err != nil {
+			return Message{}, err
+		}
+
+		point.BatteryState = model.BatteryGood
+		if battery <= lowBattery {
+			point.BatteryState = model.BatteryLow
+		}
+	}
+
+	return Message{Token: token, Point: point}, nil
+}
+
+func parseFloat(values url.Values, name string, required bool) (float64, error) {
+	value := values.Get(name)
+	if value == "" {
+		if required {
+			return 0, fmt.Errorf("%w: %s", ErrMissingParameter, name)
+		}
+
+		return 0, nil
+	}
+
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %s: %w", ErrInvalidParameter, name, err)
+	}
+
+	return number, nil
+}
+
+// parseTimestamp parses the time of the position, the time of the request if empty.
+func parseTimestamp(value string) (time.Time, error) {
+	if value == "" {
+		return time.Now().UTC().Truncate(time.Second), nil
+	}
+
+	if timestamp, err := strconv.ParseInt(value, 10, 64); err == nil {
+		if timestamp > millisecondsThreshold {
+			return time.UnixMilli(timestamp).UTC(), nil
+		}
+
+		return time.Unix(timestamp, 0).UTC(), nil
+	}
+
+	dateTime, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: timestamp: %w", ErrInvalidParameter, err)
+	}
+
+	return dateTime.UTC(), nil
+}
@@ -0,0 +1,71 @@
+package osmand_test
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"fahy.xyz/livetrack/internal/model"
+	"fahy.xyz/livetrack/internal/model/osmand"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	values, err := url.ParseQuery("id=token&lat=46.62515&lon=7.20613&timestamp=1692785805&altitude=1603.7&speed=10&bearing=112.5&batt=80")
+	require.NoError(t, err)
+
+	message, err := osmand.Parse(values)
+	require.NoError(t, err)
+	assert.Equal(t, "token", message.Token)
+	assert.Equal(t, time.Date(2023, time.Month(8), 23, 10, 16, 45, 0, time.UTC), message.Point.DateTime)
+	assert.InDelta(t, 46.62515, message.Point.Latitude, 0.000001)
+	assert.InDelta(t, 7.20613, message.Point.Longitude, 0.000001)
+	assert.Equal(t, 1603, message.Point.Altitude)
+	assert.InDelta(t, 18.52, message.Point.Velocity, 0.001)
+	assert.InDelta(t, 112.5, message.Point.Course, 0.001)
+	assert.Equal(t, model.MsgTypeTrack, message.Point.MsgType)
+	assert.False(t, message.Point.IsBatteryLow())
+}
+
+func TestParse_Timestamps(t *testing.T) {
+	t.Parallel()
+
+	expected := time.Date(2023, time.Month(8), 23, 10, 16, 45, 0, time.UTC)
+
+	for _, timestamp := range []string{"1692785805", "1692785805000", "2023-08-23T12:16:45+02:00"} {
+		message, err := osmand.Parse(url.Values{"id": {"token"}, "lat": {"46.6"}, "lon": {"7.2"}, "timestamp": {timestamp}})
+		require.NoError(t, err, timestamp)
+		assert.Equal(t, expected, message.Point.DateTime, timestamp)
+	}
+}
+
+func TestParse_LowBattery(t *testing.T) {
+	t.Parallel()
+
+	message, err := osmand.Parse(url.Values{"deviceid": {"token"}, "lat": {"46.6"}, "lon": {"7.2"}, "batt": {"12"}})
+	require.NoError(t, err)
+	assert.Equal(t, "token", message.Token)
+	assert.True(t, message.Point.IsBatteryLow())
+}
+
+func TestParse_Errors(t *testing.T) {
+	t.Parallel()
+
+	_, err := osmand.Parse(url.Values{"lat": {"46.6"}, "lon": {"7.2"}})
+	require.ErrorIs(t, err, osmand.ErrMissingParameter)
+
+	_, err = osmand.Parse(url.Values{"id": {"token"}, "lon": {"7.2"}})
+	require.ErrorIs(t, err, osmand.ErrMissingParameter)
+
+	_, err = osmand.Parse(url.Values{"id": {"token"}, "lat": {"north"}, "lon": {"7.2"}})
+	require.ErrorIs(t, err, osmand.ErrInvalidParameter)
+
+	_, err = osmand.Parse(url.Values{"id": {"token"}, "lat": {"96.6"}, "lon": {"7.2"}})
+	require.ErrorIs(t, err, osmand.ErrInvalidParameter)
+
+	_, err = osmand.Parse(url.Values{"id": {"token"}, "lat": {"46.6"}, "lon": {"7.2"}, "timestamp": {"yesterday"}})
+	require.ErrorIs(t, err, osmand.ErrInvalidParameter)
+}
@@ -0,0 +1,18 @@
+package model
+
+import "time"
+
+// Payload is a raw response of a tracker source, archived to be parsed again.
+type Payload struct {
+	ID         int64     `db:"id"          json:"id"`
+	PilotID    string    `db:"pilot_id"    json:"pilotId"`
+	Source     string    `db:"source"      json:"source"`
+	StatusCode int       `db:"status_code" json:"statusCode"`
+	FetchedAt  time.Time `db:"fetched_at"  json:"fetchedAt"`
+	Content    []byte    `db:"content"     json:"content"`
+}
+
+// IsSuccess returns true if the source answered successfully.
+func (p *Payload) IsSuccess() bool {
+	return p.StatusCode >= 200 && p.StatusCode < 300
+}
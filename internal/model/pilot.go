@@ -3,18 +3,42 @@ package model
 import (
 	"encoding/json"
 	"fmt"
+	"log/slog"
 	"net/http"
 	"net/url"
 	"time"
 )
 
 type Pilot struct {
-	ID          string   `db:"id"           json:"id"`
-	Name        string   `db:"name"         json:"name"`
-	Points      []Point  `json:"points"`
-	Home        string   `db:"home"         json:"home"`
-	Orgs        []string `db:"orgs"         json:"orgs"`
-	TrackerType string   `db:"tracker_type" json:"trackerType"`
+	ID           string   `db:"id"            json:"id"`
+	Name         string   `db:"name"          json:"name"`
+	Points       []Point  `json:"points"`
+	Home         string   `db:"home"          json:"home"`
+	Orgs         []string `db:"orgs"          json:"orgs"`
+	TrackerType  string   `db:"tracker_type"  json:"trackerType"`
+	FeedPassword Secret   `db:"feed_password" json:"-"`
+}
+
+// Secret is a string value that is redacted when printed or logged.
+type Secret string
+
+const redacted = "[REDACTED]"
+
+func (s Secret) String() string {
+	if s == "" {
+		return ""
+	}
+
+	return redacted
+}
+
+func (s Secret) GoString() string {
+	return s.String()
+}
+
+// LogValue hides the secret from the logs.
+func (s Secret) LogValue() slog.Value {
+	return slog.StringValue(s.String())
 }
 
 const (
@@ -28,15 +52,25 @@ func (p *Pilot) GetCumulativeDistance() float64 {
 	for i := range len(p.Points) - 2 {
 		startPoint := p.Points[i]
 		endPoint := p.Points[i+1]
-		dist += distance(startPoint.Latitude, startPoint.Longitude, endPoint.Latitude, endPoint.Longitude)
+		dist += startPoint.DistanceTo(endPoint)
 	}
 
 	return dist
 }
 
-// GetFlightTime returns the duration between the first and last point.
+// GetFlightTime returns the time spent flying, the duration between the first and last point if no flight is detected.
 func (p *Pilot) GetFlightTime() time.Duration {
-	return p.Points[len(p.Points)-1].DateTime.Sub(p.Points[0].DateTime)
+	flights := Flights(SplitFlights(p.Points))
+	if len(flights) == 0 {
+		return p.Points[len(p.Points)-1].DateTime.Sub(p.Points[0].DateTime)
+	}
+
+	var flightTime time.Duration
+	for _, flight := range flights {
+		flightTime += flight.Duration
+	}
+
+	return flightTime
 }
 
 // GetLivetrackURL returns the pilot's link for the livetrack of the day.
@@ -83,9 +117,45 @@ func (p *Pilot) GetSbbItinerary(latitude, longitude float64) (string, error) {
 	return response.URL, nil
 }
 
+// GetTakeOffDistance returns the distance from the takeoff of the last flight, or the first point if no flight
+// is detected, to the last point.
 func (p *Pilot) GetTakeOffDistance() float64 {
 	startPoint := p.Points[0]
+	if flights := Flights(SplitFlights(p.Points)); len(flights) > 0 {
+		startPoint = flights[len(flights)-1].Start
+	}
+
 	endPoint := p.Points[len(p.Points)-1]
 
-	return distance(startPoint.Latitude, startPoint.Longitude, endPoint.Latitude, endPoint.Longitude)
+	return startPoint.DistanceTo(endPoint)
+}
+
+// LegStatistics are the extremes over the legs between the points of the pilot.
+type LegStatistics struct {
+	// MaxSpeed is the fastest ground speed of a leg, in km/h.
+	MaxSpeed float64 `json:"maxSpeed"`
+	// MaxClimb and MaxSink are the highest and lowest vertical speeds of a leg, in m/s.
+	MaxClimb float64 `json:"maxClimb"`
+	MaxSink  float64 `json:"maxSink"`
+	// BestGlide is the highest glide ratio of a descending leg.
+	BestGlide float64 `json:"bestGlide"`
+}
+
+// GetLegStatistics returns the extremes of the leg speed, the vertical speed and the glide ratio of the points.
+func (p *Pilot) GetLegStatistics() LegStatistics {
+	return legStatistics(ComputeStatistics(p.Points))
+}
+
+// legStatistics returns the extremes of the points with their statistics computed.
+func legStatistics(points []Point) LegStatistics {
+	var stats LegStatistics
+
+	for _, point := range points {
+		stats.MaxSpeed = max(stats.MaxSpeed, point.LegSpeed)
+		stats.MaxClimb = max(stats.MaxClimb, point.VerticalSpeed)
+		stats.MaxSink = min(stats.MaxSink, point.VerticalSpeed)
+		stats.BestGlide = max(stats.BestGlide, point.GlideRatio)
+	}
+
+	return stats
 }
@@ -1,15 +1,19 @@
 package model_test
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
 	"log/slog"
 	"os"
 	"slices"
 	"testing"
+	"time"
 
 	"fahy.xyz/livetrack/internal/model"
 	"fahy.xyz/livetrack/internal/model/spot"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 const trackFile = "spot/testdata/response_long_full.json"
@@ -61,8 +65,19 @@ func TestPilot_GetCumulativeDistance(t *testing.T) {
 func TestPilot_GetFlightTime(t *testing.T) {
 	t.Parallel()
 
+	// The last point is sent after landing.
 	flightTime := pilot.GetFlightTime()
-	assert.Equal(t, "4h12m11s", fmt.Sprint(flightTime))
+	assert.Equal(t, "4h8m27s", fmt.Sprint(flightTime))
+
+	// Only the flights are counted when hiking between them.
+	start := time.Date(2023, time.Month(7), 22, 8, 0, 0, 0, time.UTC)
+	hikeAndFly := model.Pilot{Points: generateDay(start, []phase{{60, 4, 0.3}, {30, 30, -1}, {60, 4, 0.2}, {20, 35, -1.2}})}
+	assert.Equal(t, 50*time.Minute, hikeAndFly.GetFlightTime())
+	assert.InDelta(t, 11.67, hikeAndFly.GetTakeOffDistance(), 0.01)
+
+	// Without flight, the whole day is counted.
+	hike := model.Pilot{Points: generateDay(start, []phase{{60, 4, 0.3}})}
+	assert.Equal(t, time.Hour, hike.GetFlightTime())
 }
 
 func TestPilot_GetLivetrackURL(t *testing.T) {
@@ -72,6 +87,32 @@ func TestPilot_GetLivetrackURL(t *testing.T) {
 	assert.Equal(t, "[Livetrack](https://test.xyz/?pilot=test)", url)
 }
 
+func TestPilot_FeedPassword(t *testing.T) {
+	t.Parallel()
+
+	pilotWithSecret := model.Pilot{Name: "test", FeedPassword: "secret"}
+
+	content, err := json.Marshal(pilotWithSecret)
+	require.NoError(t, err)
+	assert.NotContains(t, string(content), "secret")
+
+	var buffer bytes.Buffer
+
+	slog.New(slog.NewTextHandler(&buffer, nil)).Info("pilot", "password", pilotWithSecret.FeedPassword)
+	assert.NotContains(t, buffer.String(), "secret")
+	assert.NotContains(t, fmt.Sprintf("%v %+v %#v", pilotWithSecret, pilotWithSecret, pilotWithSecret), "secret")
+}
+
+func TestPilot_GetLegStatistics(t *testing.T) {
+	t.Parallel()
+
+	stats := pilot.GetLegStatistics()
+	assert.InDelta(t, 73.7124, stats.MaxSpeed, 0.0001)
+	assert.InDelta(t, 6.0367, stats.MaxClimb, 0.0001)
+	assert.InDelta(t, -5.3144, stats.MaxSink, 0.0001)
+	assert.InDelta(t, 64.4095, stats.BestGlide, 0.0001)
+}
+
 func TestPilot_GetTakeOffDistance(t *testing.T) {
 	t.Parallel()
 
@@ -3,23 +3,61 @@ package model
 import (
 	"database/sql/driver"
 	"fmt"
-	"math"
 	"time"
+
+	"fahy.xyz/livetrack/internal/geo"
+)
+
+const (
+	// BatteryLow is the battery state sent by a SPOT tracker when its battery is low.
+	BatteryLow = "LOW"
+	// BatteryGood is the battery state sent by a SPOT tracker when its battery is fine.
+	BatteryGood = "GOOD"
+	// MsgTypeOK is the message type sent by a SPOT tracker when the pilot has landed safely.
+	MsgTypeOK = "OK"
+	// MsgTypeHelp is the message type sent by a SPOT tracker when the pilot needs help.
+	MsgTypeHelp = "HELP"
+	// MsgTypeTrack is the message type of the tracking points of the sources without messages.
+	MsgTypeTrack = "TRACK"
+
+	metersPerKilometer = 1000
+	// minGlideHeightLoss is the height lost by a leg to compute its glide ratio, twice the vertical accuracy of a
+	// GPS fix, in meters.
+	minGlideHeightLoss = 20
 )
 
 type Point struct {
-	DateTime    time.Time     `json:"dateTime"    db:"unix_time"`
-	Latitude    float64       `json:"latitude"    db:"latitude"`
-	Longitude   float64       `json:"longitude"   db:"longitude"`
-	Altitude    int           `json:"altitude"    db:"altitude"`
-	MsgType     string        `json:"msgType"     db:"msg_type"`
-	MsgContent  string        `json:"msgContent"  db:"msg_content"`
+	DateTime   time.Time `json:"dateTime"   db:"unix_time"`
+	Latitude   float64   `json:"latitude"   db:"latitude"`
+	Longitude  float64   `json:"longitude"  db:"longitude"`
+	Altitude   int       `json:"altitude"   db:"altitude"`
+	MsgType    string    `json:"msgType"    db:"msg_type"`
+	MsgContent string    `json:"msgContent" db:"msg_content"`
+	// Device metadata, empty if not sent by the tracker.
+	DeviceModel   string `json:"deviceModel,omitempty"   db:"device_model"`
+	MessengerName string `json:"messengerName,omitempty" db:"messenger_name"`
+	IMEI          string `json:"imei,omitempty"          db:"imei"`
+	BatteryState  string `json:"batteryState,omitempty"  db:"battery_state"`
+	Hidden        bool   `json:"hidden"                  db:"hidden"`
+	ValidGPSFix   bool   `json:"validGpsFix"             db:"valid_gps_fix"`
+	// Telemetry, zero if not sent by the tracker.
+	Velocity  float64 `json:"velocity"  db:"velocity"`
+	Course    float64 `json:"course"    db:"course"`
+	Emergency bool    `json:"emergency" db:"emergency"`
+	// Outlier is the reason the point is flagged as a bad fix, empty if the point is valid.
+	Outlier Outlier `json:"outlier,omitempty" db:"outlier"`
+	// Statistics computed from the previous points, the distances in km and the speeds in km/h.
 	FlightTime  time.Duration `json:"flightTime"`
 	TakeOffDist float64       `json:"takeOffDist"`
 	CumDist     float64       `json:"cumDist"`
 	AvgSpeed    float64       `json:"avgSpeed"`
 	LegSpeed    float64       `json:"legSpeed"`
 	LegDist     float64       `json:"legDist"`
+	// VerticalSpeed is the climb (positive) or sink (negative) rate since the previous point, in m/s.
+	VerticalSpeed float64 `json:"verticalSpeed"`
+	// GlideRatio is the distance flown by meter of height lost since the previous point, zero if not descending
+	// by at least minGlideHeightLoss.
+	GlideRatio float64 `json:"glideRatio"`
 }
 
 // Value represent a point in the database.
@@ -27,6 +65,30 @@ func (p *Point) Value() (driver.Value, error) {
 	return fmt.Sprintf("(%f,%f,%d,%s,%s)", p.Latitude, p.Longitude, p.Altitude, p.MsgType, p.MsgContent), nil
 }
 
+// IsBatteryLow returns true if the tracker reported a low battery.
+func (p *Point) IsBatteryLow() bool {
+	return p.BatteryState == BatteryLow
+}
+
+// IsEmergency returns true if the pilot asked for help, with a HELP message or an SOS.
+func (p *Point) IsEmergency() bool {
+	return p.Emergency || p.MsgType == MsgTypeHelp
+}
+
+// IsMessage returns true if the point is a message sent by the pilot, such as OK or HELP, rather than a tracking point.
+func (p *Point) IsMessage() bool {
+	if p.IsEmergency() || p.MsgContent != "" {
+		return true
+	}
+
+	switch p.MsgType {
+	case MsgTypeOK, "MOVE", "CUSTOM", "START", "OFF":
+		return true
+	default:
+		return false
+	}
+}
+
 func (p *Point) GetItineraryURL() string {
 	baseURL := "https://www.google.com/maps/dir/?api=1&destination="
 	linkName := "[Pick Me]"
@@ -41,7 +103,7 @@ func (p *Point) ComputeFlightTime(start Point) {
 }
 
 func (p *Point) ComputeTakeOffDist(start Point) {
-	p.TakeOffDist = distance(start.Latitude, start.Longitude, p.Latitude, p.Longitude)
+	p.TakeOffDist = p.DistanceTo(start)
 }
 
 func (p *Point) ComputeCumDist(previous Point) {
@@ -52,55 +114,102 @@ func (p *Point) ComputeAvgSpeed() {
 	p.AvgSpeed = p.CumDist / p.FlightTime.Hours()
 }
 
-func (p *Point) ComputeLegSpeed(_ Point) {
-	// TODO -> legDist / diff datetime previous and current point
+// ComputeLegSpeed computes the ground speed since the previous point, the leg distance must be computed.
+func (p *Point) ComputeLegSpeed(previous Point) {
+	elapsed := p.DateTime.Sub(previous.DateTime)
+	if elapsed <= 0 {
+		p.LegSpeed = 0
+
+		return
+	}
+
+	p.LegSpeed = p.LegDist / elapsed.Hours()
+}
+
+// ComputeVerticalSpeed computes the climb or sink rate since the previous point.
+func (p *Point) ComputeVerticalSpeed(previous Point) {
+	elapsed := p.DateTime.Sub(previous.DateTime)
+	if elapsed <= 0 {
+		p.VerticalSpeed = 0
+
+		return
+	}
+
+	p.VerticalSpeed = float64(p.Altitude-previous.Altitude) / elapsed.Seconds()
+}
+
+// ComputeGlideRatio computes the glide ratio since the previous point, the leg distance must be computed.
+//
+// A leg losing less than minGlideHeightLoss has no glide ratio, a few meters lost over kilometers are a level
+// flight within the accuracy of the altitude, not a glide of several hundreds.
+func (p *Point) ComputeGlideRatio(previous Point) {
+	heightLost := previous.Altitude - p.Altitude
+	if heightLost < minGlideHeightLoss {
+		p.GlideRatio = 0
+
+		return
+	}
+
+	p.GlideRatio = p.LegDist * metersPerKilometer / float64(heightLost)
+}
+
+// Position returns the position of the point on the WGS84 ellipsoid.
+func (p *Point) Position() geo.Position {
+	return geo.Position{Latitude: p.Latitude, Longitude: p.Longitude}
+}
+
+// DistanceTo returns the geodesic distance to the other point, in km.
+func (p *Point) DistanceTo(other Point) float64 {
+	return geo.Distance(p.Position(), other.Position())
 }
 
 func (p *Point) ComputeLegDist(previous Point) {
-	p.LegDist = distance(previous.Latitude, previous.Longitude, p.Latitude, p.Longitude)
+	p.LegDist = previous.DistanceTo(*p)
 }
 
+// ComputeStatistics returns a copy of the points with their statistics computed from the first valid point.
+//
+// The outliers have no statistics, the statistics of the next point are computed from the previous valid point.
 func ComputeStatistics(points []Point) []Point {
-	pointsWithStats := []Point{}
+	pointsWithStats := make([]Point, 0, len(points))
+	first, previous := -1, -1
 
 	for i, point := range points {
-		// Skip the first point.
-		if i == 0 {
-			pointsWithStats = append(pointsWithStats, points[0])
+		// The first point has no statistics, it may have been computed from an earlier point.
+		if first < 0 || point.IsOutlier() {
+			point.clearStatistics()
+			pointsWithStats = append(pointsWithStats, point)
+
+			if !point.IsOutlier() {
+				first, previous = i, i
+			}
 
 			continue
 		}
 
-		previous := pointsWithStats[i-1]
-
-		point.ComputeFlightTime(points[0])
-		point.ComputeTakeOffDist(points[0])
-		point.ComputeLegDist(previous)
-		point.ComputeCumDist(previous)
+		point.ComputeFlightTime(pointsWithStats[first])
+		point.ComputeTakeOffDist(pointsWithStats[first])
+		point.ComputeLegDist(pointsWithStats[previous])
+		point.ComputeCumDist(pointsWithStats[previous])
 		point.ComputeAvgSpeed()
+		point.ComputeLegSpeed(pointsWithStats[previous])
+		point.ComputeVerticalSpeed(pointsWithStats[previous])
+		point.ComputeGlideRatio(pointsWithStats[previous])
 
 		pointsWithStats = append(pointsWithStats, point)
+		previous = i
 	}
 
 	return pointsWithStats
 }
 
-func distance(lat1 float64, lng1 float64, lat2 float64, lng2 float64) float64 {
-	radlat1 := math.Pi * lat1 / 180
-	radlat2 := math.Pi * lat2 / 180
-
-	theta := lng1 - lng2
-	radtheta := math.Pi * theta / 180
-
-	dist := math.Sin(radlat1)*math.Sin(radlat2) + math.Cos(radlat1)*math.Cos(radlat2)*math.Cos(radtheta)
-
-	if dist > 1 {
-		dist = 1
-	}
-
-	dist = math.Acos(dist)
-	dist = dist * 180 / math.Pi
-	dist = dist * 60 * 1.1515
-
-	return dist * 1.609344
+func (p *Point) clearStatistics() {
+	p.FlightTime = 0
+	p.TakeOffDist = 0
+	p.CumDist = 0
+	p.AvgSpeed = 0
+	p.LegSpeed = 0
+	p.LegDist = 0
+	p.VerticalSpeed = 0
+	p.GlideRatio = 0
 }
@@ -0,0 +1,130 @@
+package model_test
+
+import (
+	"testing"
+	"time"
+
+	"fahy.xyz/livetrack/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPoint_IsMessage(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		point    model.Point
+		expected bool
+	}{
+		{name: "spot track", point: model.Point{MsgType: "UNLIMITED-TRACK"}, expected: false},
+		{name: "track", point: model.Point{MsgType: model.MsgTypeTrack}, expected: false},
+		{name: "garmin track", point: model.Point{MsgType: "Tracking message received."}, expected: false},
+		{name: "ok", point: model.Point{MsgType: model.MsgTypeOK}, expected: true},
+		{name: "help", point: model.Point{MsgType: model.MsgTypeHelp}, expected: true},
+		{name: "sos", point: model.Point{MsgType: "SOS declared from device.", Emergency: true}, expected: true},
+		{name: "garmin text", point: model.Point{MsgType: "Msg to shared map received", MsgContent: "Landed"}, expected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.expected, tt.point.IsMessage())
+		})
+	}
+}
+
+func TestComputeStatistics(t *testing.T) {
+	t.Parallel()
+
+	// The first points of the SPOT fixture, level then sinking and climbing at the third leg.
+	points := model.ComputeStatistics(pilot.Points)
+
+	tests := []struct {
+		name          string
+		index         int
+		legDist       float64
+		legSpeed      float64
+		verticalSpeed float64
+		glideRatio    float64
+	}{
+		{name: "first point", index: 0},
+		{name: "nearly level", index: 1, legDist: 2.0803, legSpeed: 25.1313, verticalSpeed: -0.0403},
+		{name: "sinking", index: 2, legDist: 3.1445, legSpeed: 37.2373, verticalSpeed: -1.0362, glideRatio: 9.9825},
+		{name: "climbing", index: 3, legDist: 1.3581, legSpeed: 16.6864, verticalSpeed: 1.2799, glideRatio: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			point := points[tt.index]
+			assert.InDelta(t, tt.legDist, point.LegDist, 0.0001)
+			assert.InDelta(t, tt.legSpeed, point.LegSpeed, 0.0001)
+			assert.InDelta(t, tt.verticalSpeed, point.VerticalSpeed, 0.0001)
+			assert.InDelta(t, tt.glideRatio, point.GlideRatio, 0.0001)
+		})
+	}
+}
+
+func TestPoint_ComputeLegStatistics(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2023, time.Month(7), 22, 11, 27, 0, 0, time.UTC)
+	previous := model.Point{DateTime: start, Altitude: 1500}
+
+	tests := []struct {
+		name          string
+		point         model.Point
+		legSpeed      float64
+		verticalSpeed float64
+		glideRatio    float64
+	}{
+		{
+			name:          "glide",
+			point:         model.Point{DateTime: start.Add(time.Minute), Altitude: 1440, LegDist: 0.6},
+			legSpeed:      36,
+			verticalSpeed: -1,
+			glideRatio:    10,
+		},
+		{
+			name:          "thermal",
+			point:         model.Point{DateTime: start.Add(time.Minute), Altitude: 1650, LegDist: 0.2},
+			legSpeed:      12,
+			verticalSpeed: 2.5,
+		},
+		{
+			name:     "level",
+			point:    model.Point{DateTime: start.Add(30 * time.Minute), Altitude: 1500, LegDist: 15},
+			legSpeed: 30,
+		},
+		{
+			name:          "nearly level",
+			point:         model.Point{DateTime: start.Add(5 * time.Minute), Altitude: 1495, LegDist: 3},
+			legSpeed:      36,
+			verticalSpeed: -1.0 / 60,
+			// Too little height lost for a glide ratio of 600.
+		},
+		{
+			name:  "same time",
+			point: model.Point{DateTime: start, Altitude: 1400, LegDist: 0.1},
+			// No speed without elapsed time, the height lost still gives the glide ratio.
+			glideRatio: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			point := tt.point
+			point.ComputeLegSpeed(previous)
+			point.ComputeVerticalSpeed(previous)
+			point.ComputeGlideRatio(previous)
+
+			assert.InDelta(t, tt.legSpeed, point.LegSpeed, 0.0001)
+			assert.InDelta(t, tt.verticalSpeed, point.VerticalSpeed, 0.0001)
+			assert.InDelta(t, tt.glideRatio, point.GlideRatio, 0.0001)
+		})
+	}
+}
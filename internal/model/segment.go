@@ -0,0 +1,192 @@
+package model
+
+import (
+	"math"
+	"time"
+)
+
+// SegmentKind tells if the pilot was flying or on the ground during a segment.
+type SegmentKind string
+
+const (
+	SegmentFlight SegmentKind = "flight"
+	SegmentGround SegmentKind = "ground"
+)
+
+const (
+	// minFlightSpeed is the ground speed above which a leg is flown, faster than a hike, in km/h.
+	minFlightSpeed = 15.0
+	// maxFlightSpeed is the ground speed above which a leg is driven, in km/h.
+	maxFlightSpeed = 120.0
+	// maxGlideSpeed is the ground speed above which a level leg is driven, in km/h.
+	maxGlideSpeed = 60.0
+	// levelVerticalSpeed is the climb or sink rate below which a leg is level, in m/s.
+	levelVerticalSpeed = 0.5
+	// minFlightVerticalSpeed is the climb or sink rate above which a slow leg is flown, e.g. in a thermal, in m/s.
+	minFlightVerticalSpeed = 1.0
+	// minGroundDuration is the shortest stop splitting two flights, a shorter one is part of the flight.
+	// Two legs of a tracker sending every 5 minutes can be slow in a thermal.
+	minGroundDuration = 15 * time.Minute
+	// minFlightDuration is the shortest flight, a shorter one is part of the ground segment, e.g. a run.
+	minFlightDuration = 2 * time.Minute
+)
+
+// Segment is a flight or a part of the day on the ground, such as a hike or a drive, with its statistics.
+//
+// The takeoff is the start of a flight and the landing its end, they are shared with the ground segments around.
+type Segment struct {
+	Kind  SegmentKind `json:"kind"`
+	Start Point       `json:"start"`
+	End   Point       `json:"end"`
+	// Duration is the time between the start and the end of the segment.
+	Duration time.Duration `json:"duration"`
+	// Distance is the distance along the points, StraightDistance between the start and the end, in km.
+	Distance         float64 `json:"distance"`
+	StraightDistance float64 `json:"straightDistance"`
+	MaxAltitude      int     `json:"maxAltitude"`
+	LegStatistics
+	// Points of the segment, with the statistics computed from the start of the segment.
+	Points []Point `json:"-"`
+}
+
+// SplitFlights splits the points of a day into flights and ground segments, sorted by time, without the outliers.
+//
+// A leg between two points is flown if its speed is between the speeds of a hike and of a car, or if its
+// vertical speed is the one of a thermal or of a steep glide. The short stops are part of the flights around and
+// the short flights are part of the ground segments, so a few wrong legs do not split a flight.
+func SplitFlights(points []Point) []Segment {
+	points = ComputeStatistics(WithoutOutliers(points))
+	if len(points) == 0 {
+		return []Segment{}
+	}
+
+	// The bounds are the indexes of the first and last points of each segment, the kinds alternate.
+	type bounds struct {
+		kind        SegmentKind
+		first, last int
+	}
+
+	segments := []bounds{{kind: SegmentGround, first: 0, last: 0}}
+
+	for i := 1; i < len(points); i++ {
+		kind := SegmentGround
+		if isFlown(points[i-1], points[i]) {
+			kind = SegmentFlight
+		}
+
+		// The first leg gives its kind to the first segment.
+		current := &segments[len(segments)-1]
+		if current.kind == kind || current.first == current.last {
+			current.kind, current.last = kind, i
+
+			continue
+		}
+
+		segments = append(segments, bounds{kind: kind, first: i - 1, last: i})
+	}
+
+	duration := func(b bounds) time.Duration {
+		return points[b.last].DateTime.Sub(points[b.first].DateTime)
+	}
+
+	// The short stops between two flights are flown, then the short flights are on the ground.
+	for _, fix := range []struct {
+		kind    SegmentKind
+		minimum time.Duration
+		inner   bool
+	}{
+		{kind: SegmentGround, minimum: minGroundDuration, inner: true},
+		{kind: SegmentFlight, minimum: minFlightDuration},
+	} {
+		merged := make([]bounds, 0, len(segments))
+
+		for i, segment := range segments {
+			inner := i > 0 && i < len(segments)-1
+			if segment.kind == fix.kind && duration(segment) < fix.minimum && (inner || !fix.inner) {
+				segment.kind = oppositeKind(fix.kind)
+			}
+
+			if len(merged) > 0 && merged[len(merged)-1].kind == segment.kind {
+				merged[len(merged)-1].last = segment.last
+
+				continue
+			}
+
+			merged = append(merged, segment)
+		}
+
+		segments = merged
+	}
+
+	result := make([]Segment, 0, len(segments))
+	for _, segment := range segments {
+		result = append(result, newSegment(segment.kind, points[segment.first:segment.last+1]))
+	}
+
+	return result
+}
+
+// Flights returns the flights of the segments.
+func Flights(segments []Segment) []Segment {
+	flights := []Segment{}
+
+	for _, segment := range segments {
+		if segment.Kind == SegmentFlight {
+			flights = append(flights, segment)
+		}
+	}
+
+	return flights
+}
+
+// isFlown returns true if the leg from the previous point to the point, with its statistics computed, is flown.
+//
+// The vertical speed is ignored if an altitude is unknown, the trackers send 0 without GPS altitude.
+// A drive slower than maxGlideSpeed, or descending a road, cannot be told from a glide.
+func isFlown(previous, point Point) bool {
+	knownAltitude := previous.Altitude != 0 && point.Altitude != 0
+	verticalSpeed := math.Abs(point.VerticalSpeed)
+
+	switch {
+	case point.LegSpeed > maxFlightSpeed:
+		return false
+	case point.LegSpeed > maxGlideSpeed:
+		// A fast leg without climb or sink is a car on a road.
+		return !knownAltitude || verticalSpeed >= levelVerticalSpeed
+	case point.LegSpeed >= minFlightSpeed:
+		return true
+	default:
+		return knownAltitude && verticalSpeed >= minFlightVerticalSpeed
+	}
+}
+
+func oppositeKind(kind SegmentKind) SegmentKind {
+	if kind == SegmentFlight {
+		return SegmentGround
+	}
+
+	return SegmentFlight
+}
+
+func newSegment(kind SegmentKind, points []Point) Segment {
+	points = ComputeStatistics(points)
+	start, end := points[0], points[len(points)-1]
+
+	segment := Segment{
+		Kind:             kind,
+		Start:            start,
+		End:              end,
+		Duration:         end.DateTime.Sub(start.DateTime),
+		Distance:         end.CumDist,
+		StraightDistance: end.TakeOffDist,
+		MaxAltitude:      start.Altitude,
+		LegStatistics:    legStatistics(points),
+		Points:           points,
+	}
+
+	for _, point := range points {
+		segment.MaxAltitude = max(segment.MaxAltitude, point.Altitude)
+	}
+
+	return segment
+}
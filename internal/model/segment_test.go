@@ -0,0 +1,127 @@
+package model_test
+
+import (
+	"testing"
+	"time"
+
+	"fahy.xyz/livetrack/internal/geo"
+	"fahy.xyz/livetrack/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// phase is a part of a generated day, at a constant ground speed (km/h) and vertical speed (m/s).
+type phase struct {
+	minutes       int
+	speed         float64
+	verticalSpeed float64
+}
+
+// generateDay returns a point by minute heading north, following the phases.
+func generateDay(start time.Time, phases []phase) []model.Point {
+	point := model.Point{DateTime: start, Latitude: 46.5, Longitude: 7.5, Altitude: 600}
+	points := []model.Point{point}
+
+	for _, p := range phases {
+		for range p.minutes {
+			point.DateTime = point.DateTime.Add(time.Minute)
+			position := geo.Destination(point.Position(), 0, p.speed/60)
+			point.Latitude, point.Longitude = position.Latitude, position.Longitude
+			point.Altitude += int(p.verticalSpeed * 60)
+			points = append(points, point)
+		}
+	}
+
+	return points
+}
+
+func TestSplitFlights(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2023, time.Month(7), 22, 8, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		phases   []phase
+		expected []model.SegmentKind
+		// Start and end of the segments, in minutes from the start of the day.
+		bounds [][2]int
+	}{
+		{
+			name:     "hike and fly twice then drive home",
+			phases:   []phase{{60, 4, 0.3}, {30, 30, -1}, {60, 4, 0.2}, {10, 35, -1.2}, {5, 5, 1.5}, {10, 35, -1.2}, {5, 0, 0}, {30, 80, 0}},
+			expected: []model.SegmentKind{model.SegmentGround, model.SegmentFlight, model.SegmentGround, model.SegmentFlight, model.SegmentGround},
+			bounds:   [][2]int{{0, 60}, {60, 90}, {90, 150}, {150, 175}, {175, 210}},
+		},
+		{
+			name:     "short stop in the flight",
+			phases:   []phase{{20, 30, -1}, {10, 3, 0}, {20, 30, -1}},
+			expected: []model.SegmentKind{model.SegmentFlight},
+			bounds:   [][2]int{{0, 50}},
+		},
+		{
+			name:     "short run",
+			phases:   []phase{{30, 4, 0.2}, {1, 16, 0}, {30, 4, 0.2}},
+			expected: []model.SegmentKind{model.SegmentGround},
+			bounds:   [][2]int{{0, 61}},
+		},
+		{
+			name:     "highway",
+			phases:   []phase{{30, 130, -1}},
+			expected: []model.SegmentKind{model.SegmentGround},
+			bounds:   [][2]int{{0, 30}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			segments := model.SplitFlights(generateDay(start, tt.phases))
+			require.Len(t, segments, len(tt.expected))
+
+			for i, segment := range segments {
+				assert.Equal(t, tt.expected[i], segment.Kind, "segment %d", i)
+				assert.Equal(t, start.Add(time.Duration(tt.bounds[i][0])*time.Minute), segment.Start.DateTime, "segment %d", i)
+				assert.Equal(t, start.Add(time.Duration(tt.bounds[i][1])*time.Minute), segment.End.DateTime, "segment %d", i)
+			}
+		})
+	}
+}
+
+func TestSplitFlights_Statistics(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2023, time.Month(7), 22, 8, 0, 0, 0, time.UTC)
+	segments := model.SplitFlights(generateDay(start, []phase{{60, 4, 0.3}, {30, 30, -1}, {10, 2, 0}}))
+	require.Len(t, segments, 3)
+
+	flight := segments[1]
+	assert.Equal(t, 30*time.Minute, flight.Duration)
+	assert.InDelta(t, 15, flight.Distance, 0.01)
+	assert.InDelta(t, 15, flight.StraightDistance, 0.01)
+	assert.Equal(t, 1680, flight.MaxAltitude)
+	assert.InDelta(t, 30, flight.MaxSpeed, 0.01)
+	assert.InDelta(t, -1, flight.MaxSink, 0.01)
+	assert.InDelta(t, 8.33, flight.BestGlide, 0.01)
+
+	// The statistics of the points start at the takeoff.
+	assert.Zero(t, flight.Points[0].CumDist)
+	assert.Equal(t, 30*time.Minute, flight.Points[len(flight.Points)-1].FlightTime)
+
+	assert.Equal(t, []model.Segment{flight}, model.Flights(segments))
+}
+
+func TestSplitFlights_Fixture(t *testing.T) {
+	t.Parallel()
+
+	// The SPOT fixture is a single flight, the last point is sent after landing.
+	segments := model.SplitFlights(pilot.Points)
+	require.Len(t, segments, 2)
+	assert.Equal(t, model.SegmentFlight, segments[0].Kind)
+	assert.Equal(t, "4h8m27s", segments[0].Duration.String())
+	assert.Equal(t, model.SegmentGround, segments[1].Kind)
+
+	assert.Empty(t, model.SplitFlights(nil))
+	assert.Len(t, model.SplitFlights(pilot.Points[:1]), 1)
+}
@@ -14,8 +14,27 @@ type Root struct {
 
 type Response struct {
 	FeedMessageResponse FeedMessageResponse `json:"feedMessageResponse"`
+	Errors              Errors              `json:"errors"`
 }
 
+// Errors contains the error returned by the feed instead of the messages.
+type Errors struct {
+	Error Error `json:"error"`
+}
+
+type Error struct {
+	Code        string `json:"code"`
+	Text        string `json:"text"`
+	Description string `json:"description"`
+}
+
+const (
+	// ErrorCodeNoMessages is returned when the feed has no message for the given dates.
+	ErrorCodeNoMessages = "E-0195"
+	// ErrorCodeFeedNotFound is returned when the feed does not exist or is not shared.
+	ErrorCodeFeedNotFound = "E-0160"
+)
+
 type FeedMessageResponse struct {
 	Count         int      `json:"count"`
 	Feed          Feed     `json:"feed"`
@@ -49,7 +68,7 @@ func (v *Content) UnmarshalJSON(point []byte) error {
 	if point[0] == '[' { // First char is '[', so it's a JSON array
 		s := make([]MessageContent, 0)
 		if err := json.Unmarshal(point, &s); err != nil {
-			return fmt.Errorf("error unmarshalling array of point %s: %w", string(point), err)
+			return fmt.Errorf("error unmarshalling array of points: %w", err)
 		}
 
 		*v = Content(s)
@@ -60,7 +79,7 @@ func (v *Content) UnmarshalJSON(point []byte) error {
 	*v = make(Content, 1)
 
 	if err := json.Unmarshal(point, &(*v)[0]); err != nil {
-		return fmt.Errorf("error unmarshalling single point %s: %w", string(point), err)
+		return fmt.Errorf("error unmarshalling single point: %w", err)
 	}
 
 	return nil
@@ -94,12 +113,18 @@ func (r *Response) ToPoints() ([]model.Point, error) {
 		}
 
 		points = append(points, model.Point{
-			DateTime:    dateTime,
-			Latitude:    message.Latitude,
-			Longitude:   message.Longitude,
-			Altitude:    message.Altitude,
-			MsgType:     message.MessageType,
-			MsgContent:  message.MessageContent,
+			DateTime:      dateTime,
+			Latitude:      message.Latitude,
+			Longitude:     message.Longitude,
+			Altitude:      message.Altitude,
+			MsgType:       message.MessageType,
+			MsgContent:    message.MessageContent,
+			DeviceModel:   message.ModelID,
+			MessengerName: message.MessengerName,
+			BatteryState:  message.BatteryState,
+			Hidden:        message.Hidden != 0,
+			// The SPOT feed only contains messages with a position.
+			ValidGPSFix: true,
 			FlightTime:  0,
 			TakeOffDist: 0.0,
 			CumDist:     0.0,
@@ -115,7 +140,7 @@ func (r *Response) ToPoints() ([]model.Point, error) {
 func Parse(content []byte) (Response, error) {
 	var root Root
 	if err := json.Unmarshal(content, &root); err != nil {
-		return Response{}, fmt.Errorf("error unmarshalling content %s: %w", string(content), err)
+		return Response{}, fmt.Errorf("error unmarshalling content: %w", err)
 	}
 
 	return root.Response, nil
@@ -28,6 +28,30 @@ func TestMessagesToPoint(t *testing.T) {
 	assert.InEpsilon(t, 46.45669, points[1].Latitude, 0.1)
 	assert.InEpsilon(t, 6.88411, points[1].Longitude, 0.1)
 	assert.Equal(t, time.Date(2023, time.Month(1), 14, 7, 47, 9, 0, time.UTC), points[1].DateTime)
+	assert.Equal(t, "SPOT3", points[1].DeviceModel)
+	assert.Equal(t, "Pilot Spot", points[1].MessengerName)
+	assert.Equal(t, "GOOD", points[1].BatteryState)
+	assert.False(t, points[1].IsBatteryLow())
+	assert.False(t, points[1].Hidden)
+	assert.True(t, points[1].ValidGPSFix)
+}
+
+func TestMessagesToPoint_BatteryLow(t *testing.T) {
+	t.Parallel()
+
+	// The full response with the battery of the first track point low.
+	content, err := os.ReadFile("testdata/response_battery_low.json")
+	require.NoError(t, err)
+
+	response, err := spot.Parse(content)
+	require.NoError(t, err)
+
+	points, err := response.ToPoints()
+	require.NoError(t, err)
+	require.Len(t, points, 4)
+	assert.Equal(t, "LOW", points[3].BatteryState)
+	assert.True(t, points[3].IsBatteryLow())
+	assert.False(t, points[2].IsBatteryLow())
 }
 
 func TestMessageSingleParse(t *testing.T) {
@@ -0,0 +1,363 @@
+// Package xc scores the cross-country flights: free distance, flat triangle and FAI triangle.
+//
+// The rules are the ones of XContest: the free distance goes through up to three turnpoints, the triangles
+// are closed if the start and the end of the flight are close enough, and the closing distance is
+// subtracted from the triangle.
+package xc
+
+import (
+	"math"
+
+	"fahy.xyz/livetrack/internal/model"
+)
+
+// Kind is the type of a scored route.
+type Kind string
+
+const (
+	KindFreeDistance Kind = "freeDistance"
+	KindFlatTriangle Kind = "flatTriangle"
+	KindFAITriangle  Kind = "faiTriangle"
+)
+
+// Name returns the name of the kind in the messages.
+func (k Kind) Name() string {
+	switch k {
+	case KindFlatTriangle:
+		return "flat triangle"
+	case KindFAITriangle:
+		return "FAI triangle"
+	default:
+		return "free distance"
+	}
+}
+
+const (
+	// defaultClosingRatio is the largest closing distance of a triangle, as a ratio of its distance.
+	defaultClosingRatio = 0.2
+	// defaultFAIMinLegRatio is the shortest leg of an FAI triangle, as a ratio of its distance.
+	defaultFAIMinLegRatio = 0.28
+	// maxCandidates is the number of points of the exhaustive search, the route is then refined on all the points.
+	maxCandidates = 300
+	// routeSize is the number of points of a route: the start, three turnpoints and the end.
+	routeSize = 5
+)
+
+// Multipliers are the points by kilometer of each kind of route.
+type Multipliers struct {
+	FreeDistance float64
+	FlatTriangle float64
+	FAITriangle  float64
+}
+
+// Config holds the multipliers and the closing rules of the triangles.
+type Config struct {
+	Multipliers
+	// ClosingRatio is the largest closing distance of a triangle, as a ratio of its distance.
+	ClosingRatio float64
+	// FAIMinLegRatio is the shortest leg of an FAI triangle, as a ratio of its distance.
+	FAIMinLegRatio float64
+}
+
+// DefaultConfig returns the multipliers and the rules of XContest.
+func DefaultConfig() Config {
+	return Config{
+		Multipliers: Multipliers{
+			FreeDistance: 1.0,
+			FlatTriangle: 1.2,
+			FAITriangle:  1.4,
+		},
+		ClosingRatio:   defaultClosingRatio,
+		FAIMinLegRatio: defaultFAIMinLegRatio,
+	}
+}
+
+// Score is the best route of a kind.
+type Score struct {
+	Kind Kind `json:"kind"`
+	// Route is the start, the three turnpoints and the end of the flight.
+	Route []model.Point `json:"route"`
+	// Distance is the distance through the turnpoints, the perimeter of the triangles, in km.
+	Distance float64 `json:"distance"`
+	// ClosingDistance is the distance between the start and the end of a triangle, in km.
+	ClosingDistance float64 `json:"closingDistance"`
+	Multiplier      float64 `json:"multiplier"`
+	Points          float64 `json:"points"`
+}
+
+// Result holds the best score of the flight and the score of each kind of route found.
+type Result struct {
+	Best   *Score  `json:"best"`
+	Scores []Score `json:"scores"`
+}
+
+// Optimize returns the best routes of the points of a flight, sorted by time.
+//
+// The routes are searched exhaustively on a subset of the points, then their points are moved one at a time
+// between the points around while the score improves.
+func Optimize(points []model.Point, config Config) Result {
+	result := Result{Scores: []Score{}}
+	if len(points) < 2 { //nolint:mnd // A leg.
+		return result
+	}
+
+	optimizer := newOptimizer(points, config)
+
+	for _, kind := range []Kind{KindFreeDistance, KindFlatTriangle, KindFAITriangle} {
+		route, ok := optimizer.search(kind)
+		if !ok {
+			continue
+		}
+
+		route = optimizer.refine(kind, route)
+		score := optimizer.score(kind, route)
+
+		if score.Points <= 0 {
+			continue
+		}
+
+		result.Scores = append(result.Scores, score)
+	}
+
+	for i := range result.Scores {
+		if result.Best == nil || result.Scores[i].Points > result.Best.Points {
+			result.Best = &result.Scores[i]
+		}
+	}
+
+	return result
+}
+
+type route [routeSize]int
+
+type optimizer struct {
+	points []model.Point
+	config Config
+	// candidates are the indexes of the points of the exhaustive search, step apart.
+	candidates []int
+	step       int
+	// distances between the candidates, by index in candidates.
+	distances [][]float64
+}
+
+func newOptimizer(points []model.Point, config Config) *optimizer {
+	step := (len(points) + maxCandidates - 1) / maxCandidates
+
+	candidates := make([]int, 0, maxCandidates+1)
+	for i := 0; i < len(points); i += step {
+		candidates = append(candidates, i)
+	}
+
+	if candidates[len(candidates)-1] != len(points)-1 {
+		candidates = append(candidates, len(points)-1)
+	}
+
+	distances := make([][]float64, len(candidates))
+	for i := range candidates {
+		distances[i] = make([]float64, len(candidates))
+		for j := range i {
+			distances[i][j] = points[candidates[i]].DistanceTo(points[candidates[j]])
+			distances[j][i] = distances[i][j]
+		}
+	}
+
+	return &optimizer{
+		points:     points,
+		config:     config,
+		candidates: candidates,
+		step:       step,
+		distances:  distances,
+	}
+}
+
+// search returns the best route of the kind on the candidates, false if there is none.
+func (o *optimizer) search(kind Kind) (route, bool) {
+	if kind == KindFreeDistance {
+		return o.searchFreeDistance(), true
+	}
+
+	return o.searchTriangle(kind == KindFAITriangle)
+}
+
+// searchFreeDistance returns the longest route through the candidates, in order.
+func (o *optimizer) searchFreeDistance() route {
+	n := len(o.candidates)
+
+	// best[leg][j] is the longest route of leg legs ending at the candidate j, coming from parent[leg][j].
+	best := make([][]float64, routeSize)
+	parent := make([][]int, routeSize)
+
+	for leg := range routeSize {
+		best[leg] = make([]float64, n)
+		parent[leg] = make([]int, n)
+
+		for j := range n {
+			parent[leg][j] = j
+			if leg == 0 {
+				continue
+			}
+
+			for i := 0; i <= j; i++ {
+				if distance := best[leg-1][i] + o.distances[i][j]; distance > best[leg][j] {
+					best[leg][j], parent[leg][j] = distance, i
+				}
+			}
+		}
+	}
+
+	var last int
+	for j := range n {
+		if best[routeSize-1][j] > best[routeSize-1][last] {
+			last = j
+		}
+	}
+
+	var r route
+	for leg := routeSize - 1; leg >= 0; leg-- {
+		r[leg] = o.candidates[last]
+		last = parent[leg][last]
+	}
+
+	return r
+}
+
+// searchTriangle returns the best closed triangle through the candidates, false if there is none.
+func (o *optimizer) searchTriangle(fai bool) (route, bool) {
+	n := len(o.candidates)
+
+	// closing[i][k] is the closest pair of a start before the candidate i and an end after the candidate k.
+	type closingPair struct {
+		distance   float64
+		start, end int
+	}
+
+	closing := make([][]closingPair, n)
+	for i := range n {
+		closing[i] = make([]closingPair, n)
+		for k := n - 1; k >= i; k-- {
+			pair := closingPair{distance: o.distances[i][k], start: i, end: k}
+			if i > 0 && closing[i-1][k].distance < pair.distance {
+				pair = closing[i-1][k]
+			}
+
+			if k < n-1 && closing[i][k+1].distance < pair.distance {
+				pair = closing[i][k+1]
+			}
+
+			closing[i][k] = pair
+		}
+	}
+
+	var (
+		best  route
+		found bool
+		score float64
+	)
+
+	for i := range n {
+		for j := i + 1; j < n; j++ {
+			for k := j + 1; k < n; k++ {
+				first, second, third := o.distances[i][j], o.distances[j][k], o.distances[k][i]
+				perimeter := first + second + third
+				pair := closing[i][k]
+
+				if !o.isClosed(perimeter, pair.distance) {
+					continue
+				}
+
+				if fai && !o.isFAI(perimeter, first, second, third) {
+					continue
+				}
+
+				if value := perimeter - pair.distance; value > score {
+					best = route{o.candidates[pair.start], o.candidates[i], o.candidates[j], o.candidates[k], o.candidates[pair.end]}
+					score, found = value, true
+				}
+			}
+		}
+	}
+
+	return best, found
+}
+
+// refine moves each point of the route between the points around it while the score improves.
+func (o *optimizer) refine(kind Kind, r route) route {
+	best := o.score(kind, r).Points
+
+	for improved := true; improved; {
+		improved = false
+
+		for position := range routeSize {
+			low, high := r[position]-o.step, r[position]+o.step
+			if position > 0 {
+				low = max(low, r[position-1])
+			}
+
+			if position < routeSize-1 {
+				high = min(high, r[position+1])
+			}
+
+			low, high = max(low, 0), min(high, len(o.points)-1)
+
+			for index := low; index <= high; index++ {
+				candidate := r
+				candidate[position] = index
+
+				if points := o.score(kind, candidate).Points; points > best {
+					r, best, improved = candidate, points, true
+				}
+			}
+		}
+	}
+
+	return r
+}
+
+// score returns the score of the route, with no point if the triangle is not closed or not FAI.
+func (o *optimizer) score(kind Kind, r route) Score {
+	points := make([]model.Point, 0, routeSize)
+	for _, index := range r {
+		points = append(points, o.points[index])
+	}
+
+	score := Score{Kind: kind, Route: points}
+
+	switch kind {
+	case KindFreeDistance:
+		score.Multiplier = o.config.FreeDistance
+		for i := 1; i < routeSize; i++ {
+			score.Distance += points[i-1].DistanceTo(points[i])
+		}
+
+		score.Points = score.Distance * score.Multiplier
+
+		return score
+	case KindFAITriangle:
+		score.Multiplier = o.config.FAITriangle
+	default:
+		score.Multiplier = o.config.FlatTriangle
+	}
+
+	first := points[1].DistanceTo(points[2])
+	second := points[2].DistanceTo(points[3])
+	third := points[3].DistanceTo(points[1])
+	score.Distance = first + second + third
+	score.ClosingDistance = points[0].DistanceTo(points[4])
+
+	if !o.isClosed(score.Distance, score.ClosingDistance) ||
+		(kind == KindFAITriangle && !o.isFAI(score.Distance, first, second, third)) {
+		return score
+	}
+
+	score.Points = (score.Distance - score.ClosingDistance) * score.Multiplier
+
+	return score
+}
+
+func (o *optimizer) isClosed(perimeter, closingDistance float64) bool {
+	return perimeter > 0 && closingDistance <= o.config.ClosingRatio*perimeter
+}
+
+func (o *optimizer) isFAI(perimeter, first, second, third float64) bool {
+	return math.Min(first, math.Min(second, third)) >= o.config.FAIMinLegRatio*perimeter
+}
@@ -0,0 +1,177 @@
+package xc_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"fahy.xyz/livetrack/internal/geo"
+	"fahy.xyz/livetrack/internal/model"
+	"fahy.xyz/livetrack/internal/model/xc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// vertex is a position in km east and north of the origin of the flight.
+type vertex struct {
+	east, north float64
+}
+
+// origin is the position of the vertex at 0 km east and north.
+var origin = geo.Position{Latitude: 46.5, Longitude: 7.5}
+
+// position returns the position of the vertex, at its distance from the origin along the geodesic of its bearing.
+func (v vertex) position() geo.Position {
+	return geo.Destination(origin, math.Atan2(v.east, v.north)*180/math.Pi, math.Hypot(v.east, v.north))
+}
+
+// fly returns the points of a flight through the vertices, a point every 100 m.
+func fly(vertices ...vertex) []model.Point {
+	const spacing = 0.1
+
+	start := time.Date(2023, time.Month(7), 22, 11, 0, 0, 0, time.UTC)
+	points := []model.Point{}
+
+	appendPoint := func(position geo.Position) {
+		points = append(points, model.Point{
+			DateTime:  start.Add(time.Duration(len(points)) * 10 * time.Second),
+			Latitude:  position.Latitude,
+			Longitude: position.Longitude,
+		})
+	}
+
+	for i := 1; i < len(vertices); i++ {
+		from, to := vertices[i-1].position(), vertices[i].position()
+		distance, bearing := geo.Distance(from, to), geo.Bearing(from, to)
+		steps := int(math.Ceil(distance / spacing))
+
+		for step := range steps {
+			appendPoint(geo.Destination(from, bearing, distance*float64(step)/float64(steps)))
+		}
+	}
+
+	appendPoint(vertices[len(vertices)-1].position())
+
+	return points
+}
+
+func TestOptimize(t *testing.T) {
+	t.Parallel()
+
+	// Sides of the equilateral triangle of 10 km, with its height.
+	height := 10 * math.Sqrt(3) / 2
+
+	tests := []struct {
+		name   string
+		points []model.Point
+		best   xc.Kind
+		// Expected points by kind, zero if not found.
+		scores map[xc.Kind]float64
+	}{
+		{
+			name:   "straight line",
+			points: fly(vertex{0, 0}, vertex{0, 25}),
+			best:   xc.KindFreeDistance,
+			scores: map[xc.Kind]float64{xc.KindFreeDistance: 25},
+		},
+		{
+			// An out and return is a flat triangle with a turnpoint on a leg.
+			name:   "out and return",
+			points: fly(vertex{0, 0}, vertex{0, 10}, vertex{0, 0}),
+			best:   xc.KindFlatTriangle,
+			scores: map[xc.Kind]float64{xc.KindFreeDistance: 20, xc.KindFlatTriangle: 24},
+		},
+		{
+			name:   "FAI triangle",
+			points: fly(vertex{0, 0}, vertex{10, 0}, vertex{5, height}, vertex{0, 0}),
+			best:   xc.KindFAITriangle,
+			scores: map[xc.Kind]float64{xc.KindFreeDistance: 30, xc.KindFlatTriangle: 36, xc.KindFAITriangle: 42},
+		},
+		{
+			name:   "flat triangle",
+			points: fly(vertex{0, 0}, vertex{20, 0}, vertex{20, 4}, vertex{0, 0}),
+			best:   xc.KindFlatTriangle,
+			scores: map[xc.Kind]float64{xc.KindFreeDistance: 44.4, xc.KindFlatTriangle: 53.3},
+		},
+		{
+			name:   "open triangle",
+			points: fly(vertex{0, 0}, vertex{10, 0}, vertex{5, height}, vertex{5, 2 * height}),
+			best:   xc.KindFreeDistance,
+			scores: map[xc.Kind]float64{xc.KindFreeDistance: 28.7},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			result := xc.Optimize(tt.points, xc.DefaultConfig())
+			require.NotNil(t, result.Best)
+			assert.Equal(t, tt.best, result.Best.Kind)
+
+			scores := make(map[xc.Kind]xc.Score, len(result.Scores))
+			for _, score := range result.Scores {
+				scores[score.Kind] = score
+			}
+
+			for _, kind := range []xc.Kind{xc.KindFreeDistance, xc.KindFlatTriangle, xc.KindFAITriangle} {
+				expected, ok := tt.scores[kind]
+				if !ok {
+					// The triangles of the open flights are either not found or short.
+					assert.Less(t, scores[kind].Points, result.Best.Points, kind)
+
+					continue
+				}
+
+				require.Contains(t, scores, kind)
+				assert.InDelta(t, expected, scores[kind].Points, 0.2, kind)
+				assert.Len(t, scores[kind].Route, 5)
+			}
+		})
+	}
+}
+
+func TestOptimize_Multipliers(t *testing.T) {
+	t.Parallel()
+
+	height := 10 * math.Sqrt(3) / 2
+	points := fly(vertex{0, 0}, vertex{10, 0}, vertex{5, height}, vertex{1, 0})
+
+	config := xc.DefaultConfig()
+	config.FAITriangle = 2
+
+	result := xc.Optimize(points, config)
+	require.NotNil(t, result.Best)
+	assert.Equal(t, xc.KindFAITriangle, result.Best.Kind)
+	assert.InDelta(t, 30, result.Best.Distance, 0.2)
+	// The closing distance of 1 km is subtracted.
+	assert.InDelta(t, 1, result.Best.ClosingDistance, 0.1)
+	assert.InDelta(t, 58, result.Best.Points, 0.4)
+	assert.InDelta(t, 2, result.Best.Multiplier, 0)
+}
+
+func TestOptimize_LongFlight(t *testing.T) {
+	t.Parallel()
+
+	// A long zigzag is optimized on a subset of its points, the best turnpoints are the vertices.
+	vertices := []vertex{{0, 0}}
+	for i := range 10 {
+		vertices = append(vertices, vertex{float64(i%2) * 3, float64(i+1) * 4})
+	}
+
+	points := fly(vertices...)
+	require.Greater(t, len(points), 400)
+
+	result := xc.Optimize(points, xc.DefaultConfig())
+	require.NotNil(t, result.Best)
+	assert.Equal(t, xc.KindFreeDistance, result.Best.Kind)
+	assert.InDelta(t, 43, result.Best.Points, 0.1)
+}
+
+func TestOptimize_NoPoint(t *testing.T) {
+	t.Parallel()
+
+	result := xc.Optimize(nil, xc.DefaultConfig())
+	assert.Nil(t, result.Best)
+	assert.Empty(t, result.Scores)
+}
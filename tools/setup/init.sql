@@ -8,7 +8,10 @@ CREATE TABLE IF NOT EXISTS pilot (
    name VARCHAR(100),
    home VARCHAR(100),
    orgs VARCHAR(100)[],
-   tracker_type VARCHAR(100)
+   tracker_type VARCHAR(100),
+   feed_password VARCHAR(100),
+   ingest_token VARCHAR(100) UNIQUE,
+   upload_token VARCHAR(100) UNIQUE
 );
 
 -- track table
@@ -20,9 +23,35 @@ CREATE TABLE IF NOT EXISTS track (
     altitude INTEGER,
     msg_type VARCHAR(100),
     msg_content VARCHAR(200),
+    device_model VARCHAR(100) NOT NULL DEFAULT '',
+    messenger_name VARCHAR(100) NOT NULL DEFAULT '',
+    imei VARCHAR(20) NOT NULL DEFAULT '',
+    battery_state VARCHAR(20) NOT NULL DEFAULT '',
+    hidden BOOLEAN NOT NULL DEFAULT FALSE,
+    valid_gps_fix BOOLEAN NOT NULL DEFAULT TRUE,
+    velocity REAL NOT NULL DEFAULT 0,
+    course REAL NOT NULL DEFAULT 0,
+    emergency BOOLEAN NOT NULL DEFAULT FALSE,
+    outlier VARCHAR(20) NOT NULL DEFAULT '',
     PRIMARY KEY (pilot_id, unix_time)
 );
 
+-- uploaded track table, full-resolution flight logs uploaded after the flight
+-- the points are not notified, the table has no trigger
+CREATE TABLE IF NOT EXISTS uploaded_track (LIKE track INCLUDING ALL);
+
+-- payload table, raw responses of the trackers compressed with gzip
+CREATE TABLE IF NOT EXISTS payload (
+    id BIGSERIAL PRIMARY KEY,
+    pilot_id VARCHAR(100),
+    source VARCHAR(100),
+    status_code INTEGER,
+    fetched_at TIMESTAMPTZ,
+    content BYTEA
+);
+
+CREATE INDEX IF NOT EXISTS payload_fetched_at_idx ON payload (fetched_at);
+
 -- Create a function to send a NOTIFY event with pilot details
 CREATE FUNCTION notify_new_track_data() RETURNS TRIGGER AS $$
 DECLARE
@@ -42,6 +71,15 @@ BEGIN
         'altitude', NEW.altitude,
         'msg_type', NEW.msg_type,
         'msg_content', NEW.msg_content,
+        'device_model', NEW.device_model,
+        'messenger_name', NEW.messenger_name,
+        'imei', NEW.imei,
+        'battery_state', NEW.battery_state,
+        'hidden', NEW.hidden,
+        'valid_gps_fix', NEW.valid_gps_fix,
+        'velocity', NEW.velocity,
+        'course', NEW.course,
+        'emergency', NEW.emergency,
         'pilot', json_build_object(
             'id', pilot_data.id,
             'name', pilot_data.name,
@@ -61,6 +99,9 @@ FOR EACH ROW
 EXECUTE FUNCTION notify_new_track_data();
 
 -- insert known pilots to retrieve
-INSERT INTO pilot(id, name, home, orgs, tracker_type)
+-- the feed password is only needed for password-protected garmin feeds
+-- the ingest token is the device ID of the apps pushing their positions (osmand tracker type)
+-- the upload token is the secret of the pilot uploading its flight logs to /api/upload, whatever the tracker type
+INSERT INTO pilot(id, name, home, orgs, tracker_type, feed_password, upload_token)
 VALUES
-  ('id', 'Pilot name', 'home', '{"org1", "org2"}', 'spot');
+  ('id', 'Pilot name', 'home', '{"org1", "org2"}', 'spot', NULL, NULL);
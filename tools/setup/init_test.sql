@@ -4,7 +4,10 @@ CREATE TABLE IF NOT EXISTS pilot (
    name VARCHAR(100),
    home VARCHAR(100),
    orgs VARCHAR(100)[],
-   tracker_type VARCHAR(100)
+   tracker_type VARCHAR(100),
+   feed_password VARCHAR(100),
+   ingest_token VARCHAR(100) UNIQUE,
+   upload_token VARCHAR(100) UNIQUE
 );
 
 -- track table
@@ -16,9 +19,35 @@ CREATE TABLE IF NOT EXISTS track (
     altitude INTEGER,
     msg_type VARCHAR(100),
     msg_content VARCHAR(200),
+    device_model VARCHAR(100) NOT NULL DEFAULT '',
+    messenger_name VARCHAR(100) NOT NULL DEFAULT '',
+    imei VARCHAR(20) NOT NULL DEFAULT '',
+    battery_state VARCHAR(20) NOT NULL DEFAULT '',
+    hidden BOOLEAN NOT NULL DEFAULT FALSE,
+    valid_gps_fix BOOLEAN NOT NULL DEFAULT TRUE,
+    velocity REAL NOT NULL DEFAULT 0,
+    course REAL NOT NULL DEFAULT 0,
+    emergency BOOLEAN NOT NULL DEFAULT FALSE,
+    outlier VARCHAR(20) NOT NULL DEFAULT '',
     PRIMARY KEY (pilot_id, unix_time)
 );
 
+-- uploaded track table, full-resolution flight logs uploaded after the flight
+-- the points are not notified, the table has no trigger
+CREATE TABLE IF NOT EXISTS uploaded_track (LIKE track INCLUDING ALL);
+
+-- payload table, raw responses of the trackers compressed with gzip
+CREATE TABLE IF NOT EXISTS payload (
+    id BIGSERIAL PRIMARY KEY,
+    pilot_id VARCHAR(100),
+    source VARCHAR(100),
+    status_code INTEGER,
+    fetched_at TIMESTAMPTZ,
+    content BYTEA
+);
+
+CREATE INDEX IF NOT EXISTS payload_fetched_at_idx ON payload (fetched_at);
+
 -- Create a function to send a NOTIFY event with pilot details
 CREATE FUNCTION notify_new_track_data() RETURNS TRIGGER AS $$
 DECLARE
@@ -38,6 +67,15 @@ BEGIN
         'altitude', NEW.altitude,
         'msg_type', NEW.msg_type,
         'msg_content', NEW.msg_content,
+        'device_model', NEW.device_model,
+        'messenger_name', NEW.messenger_name,
+        'imei', NEW.imei,
+        'battery_state', NEW.battery_state,
+        'hidden', NEW.hidden,
+        'valid_gps_fix', NEW.valid_gps_fix,
+        'velocity', NEW.velocity,
+        'course', NEW.course,
+        'emergency', NEW.emergency,
         'pilot', json_build_object(
             'id', pilot_data.id,
             'name', pilot_data.name,
@@ -57,9 +95,9 @@ FOR EACH ROW
 EXECUTE FUNCTION notify_new_track_data();
 
 -- insert known pilots to retrieve
-INSERT INTO pilot(id, name, home, orgs, tracker_type)
+INSERT INTO pilot(id, name, home, orgs, tracker_type, feed_password, ingest_token, upload_token)
 VALUES
-  ('0D3D3Gdn4JqV4hEkp4TRiRoc02Hk5frJa', 'Bix', 'Ferrix', '{"rebellion","civil"}', 'spot'),
-  ('0Z7eRKM9rCcrima9ic2qqvNFjDjgf87fG', 'Cassian', 'Kenari', '{"rebellion"}', 'spot'),
-  ('0RKUQmnYcUhGflhlrrsm9jthBJo2WjNOq', 'Luthen', 'Coruscant', '{"rebellion"}', 'garmin'),
-  ('0Sqp9zyH3ZOfaWhPi4KeUd2GNfqTW43aG', 'Moff', 'Mandalore', '{"empire"}', 'spot');
+  ('0D3D3Gdn4JqV4hEkp4TRiRoc02Hk5frJa', 'Bix', 'Ferrix', '{"rebellion","civil"}', 'spot', NULL, NULL, NULL),
+  ('0Z7eRKM9rCcrima9ic2qqvNFjDjgf87fG', 'Cassian', 'Kenari', '{"rebellion"}', 'spot', NULL, NULL, 'andor'),
+  ('0RKUQmnYcUhGflhlrrsm9jthBJo2WjNOq', 'Luthen', 'Coruscant', '{"rebellion"}', 'garmin', 'axis', NULL, NULL),
+  ('0Sqp9zyH3ZOfaWhPi4KeUd2GNfqTW43aG', 'Moff', 'Mandalore', '{"empire"}', 'osmand', NULL, 'gideon', NULL);
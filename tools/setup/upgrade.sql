@@ -0,0 +1,85 @@
+-- Upgrade of a database created with an earlier init.sql, the statements can be run again.
+-- psql -f tools/setup/upgrade.sql
+
+\c tracking
+
+-- password of the password-protected garmin feeds
+ALTER TABLE pilot ADD COLUMN IF NOT EXISTS feed_password VARCHAR(100);
+
+-- payload table, raw responses of the trackers compressed with gzip
+CREATE TABLE IF NOT EXISTS payload (
+    id BIGSERIAL PRIMARY KEY,
+    pilot_id VARCHAR(100),
+    source VARCHAR(100),
+    status_code INTEGER,
+    fetched_at TIMESTAMPTZ,
+    content BYTEA
+);
+
+CREATE INDEX IF NOT EXISTS payload_fetched_at_idx ON payload (fetched_at);
+
+-- device metadata of the points
+ALTER TABLE track
+    ADD COLUMN IF NOT EXISTS device_model VARCHAR(100) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS messenger_name VARCHAR(100) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS imei VARCHAR(20) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS battery_state VARCHAR(20) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS hidden BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN IF NOT EXISTS valid_gps_fix BOOLEAN NOT NULL DEFAULT TRUE,
+    ADD COLUMN IF NOT EXISTS velocity REAL NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS course REAL NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS emergency BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- notify the device metadata of the new points, the trigger calls the replaced function
+CREATE OR REPLACE FUNCTION notify_new_track_data() RETURNS TRIGGER AS $$
+DECLARE
+    pilot_data RECORD;
+BEGIN
+    -- Fetch pilot details
+    SELECT id, name, home, orgs, tracker_type INTO pilot_data
+    FROM pilot
+    WHERE id = NEW.pilot_id;
+
+    -- Send notification with track point and pilot info
+    PERFORM pg_notify('new_track_data', json_build_object(
+        'pilot_id', NEW.pilot_id,
+        'unix_time', NEW.unix_time,
+        'latitude', NEW.latitude,
+        'longitude', NEW.longitude,
+        'altitude', NEW.altitude,
+        'msg_type', NEW.msg_type,
+        'msg_content', NEW.msg_content,
+        'device_model', NEW.device_model,
+        'messenger_name', NEW.messenger_name,
+        'imei', NEW.imei,
+        'battery_state', NEW.battery_state,
+        'hidden', NEW.hidden,
+        'valid_gps_fix', NEW.valid_gps_fix,
+        'velocity', NEW.velocity,
+        'course', NEW.course,
+        'emergency', NEW.emergency,
+        'pilot', json_build_object(
+            'id', pilot_data.id,
+            'name', pilot_data.name,
+            'home', pilot_data.home,
+            'orgs', pilot_data.orgs,
+            'tracker_type', pilot_data.tracker_type
+        )
+    )::text);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+-- token of the pilots pushing their points to the ingest endpoint
+ALTER TABLE pilot ADD COLUMN IF NOT EXISTS ingest_token VARCHAR(100) UNIQUE;
+
+-- uploaded track table, full-resolution flight logs uploaded after the flight
+-- created after the columns of track, the points are not notified, the table has no trigger
+CREATE TABLE IF NOT EXISTS uploaded_track (LIKE track INCLUDING ALL);
+
+-- token of the pilots uploading their flight logs, whatever the tracker type
+ALTER TABLE pilot ADD COLUMN IF NOT EXISTS upload_token VARCHAR(100) UNIQUE;
+
+-- reason of the points flagged as bad fixes when written, empty for the valid points
+ALTER TABLE track ADD COLUMN IF NOT EXISTS outlier VARCHAR(20) NOT NULL DEFAULT '';
+ALTER TABLE uploaded_track ADD COLUMN IF NOT EXISTS outlier VARCHAR(20) NOT NULL DEFAULT '';